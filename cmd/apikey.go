@@ -1,42 +1,85 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/internal/llm"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var apikeyCmd = &cobra.Command{
 	Use:   "apikey",
 	Short: "Manage API keys for LLM providers",
 	Long: `Manage API keys for language model providers securely.
-Keys are stored in your system's secure keyring.`,
+
+Keys are looked up in the secret stores listed in secrets.order, first match
+wins:
+  - env:     YTS_<PROVIDER>_API_KEY environment variables, e.g. YTS_CLAUDE_API_KEY
+  - keyring: your system's secure keyring
+  - file:    apikeys.enc in the config directory, encrypted with a passphrase
+             read from YTS_SECRETS_PASSPHRASE or asked for in the terminal
+
+'yts apikey set' saves keys in the store named by secrets.store (keyring or
+file). Use the file store where no keyring is available, such as headless
+Linux servers and containers.
+
+Endpoints with an api_key_ref use the key saved under that name, e.g.
+'yts apikey set groq' for an endpoint with api_key_ref groq.`,
 }
 
 var setKeyCmd = &cobra.Command{
-	Use:   "set [provider] [api-key]",
+	Use:   "set <provider> [api-key]",
 	Short: "Set API key for a provider",
-	Args:  cobra.ExactArgs(2),
+	Long: `Set the API key for a provider. Without the api-key argument, the key is
+read from stdin when it is piped, or asked for without echoing it, so it
+doesn't end up in your shell history.`,
+	Example: `  yts apikey set claude
+  yts apikey set gemini
+  pass show anthropic | yts apikey set claude`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		provider := strings.ToLower(args[0])
-		apiKey := args[1]
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
 
 		// Validate provider
-		if !isValidAPIKeyProvider(provider) {
-			return fmt.Errorf("invalid provider: %s. Valid providers: claude, openai", provider)
+		if err := validateAPIKeyProvider(cfg, provider); err != nil {
+			return err
+		}
+
+		apiKey := ""
+		if len(args) == 2 {
+			apiKey = args[1]
+		} else {
+			if apiKey, err = readAPIKey(provider); err != nil {
+				return err
+			}
+		}
+		if apiKey == "" {
+			return fmt.Errorf("API key can't be empty")
 		}
 
 		if provider == "claude" && !strings.HasPrefix(apiKey, "sk-") {
 			return fmt.Errorf("claude API keys should start with 'sk-'")
 		}
 
-		keyManager := config.NewAPIKeyManager()
-		if err := keyManager.SetAPIKey(provider, apiKey); err != nil {
+		store, err := config.NewAPIKeyManager(cfg).SetAPIKey(provider, apiKey)
+		if err != nil {
 			return fmt.Errorf("failed to set API key: %w", err)
 		}
 
+		fmt.Printf("API key for %s saved to %s\n", provider, store)
 		return nil
 	},
 }
@@ -48,30 +91,225 @@ var deleteKeyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		provider := strings.ToLower(args[0])
 
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
 		// Validate provider
-		if !isValidAPIKeyProvider(provider) {
-			return fmt.Errorf("invalid provider: %s. Valid providers: claude, openai", provider)
+		if err := validateAPIKeyProvider(cfg, provider); err != nil {
+			return err
 		}
 
-		keyManager := config.NewAPIKeyManager()
-		if err := keyManager.DeleteAPIKey(provider); err != nil {
+		if err := config.NewAPIKeyManager(cfg).DeleteAPIKey(provider); err != nil {
 			return fmt.Errorf("failed to delete API key: %w", err)
 		}
+		fmt.Printf("API key for %s deleted\n", provider)
 
 		return nil
 	},
 }
 
+var statusKeyCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which secret store supplies each API key",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		keyManager := config.NewAPIKeyManager(cfg)
+		fmt.Printf("Lookup order: %s\n", strings.Join(keyManager.Order(), ", "))
+		fmt.Printf("New keys are saved to: %s\n\n", cfg.Secrets.Store)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tSTORE\tDETAILS")
+		for _, provider := range apiKeyProviders(cfg) {
+			_, store, err := keyManager.LookupAPIKey(provider)
+			var notFound *config.ErrAPIKeyNotFound
+			if errors.As(err, &notFound) {
+				fmt.Fprintf(w, "%s\tnot set\t%s\n", provider, strings.Join(notFound.Problems, "; "))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			details := ""
+			if store == config.SecretStoreEnv {
+				details = config.APIKeyEnvVar(provider)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", provider, store, details)
+		}
+		return w.Flush()
+	},
+}
+
+var listKeyCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys, masked, with when each was last verified",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		keyManager := config.NewAPIKeyManager(cfg)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tKEY\tSTORE\tLAST VERIFIED")
+		for _, provider := range apiKeyProviders(cfg) {
+			apiKey, store, err := keyManager.LookupAPIKey(provider)
+			if err != nil {
+				fmt.Fprintf(w, "%s\t-\tnot set\t-\n", provider)
+				continue
+			}
+
+			verified := "never"
+			if t, ok := keyManager.LastVerified(provider, apiKey); ok {
+				verified = fmt.Sprintf("%s (%s ago)", t.Local().Format("2006-01-02 15:04"), formatAge(time.Since(t)))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", provider, maskAPIKey(apiKey), store, verified)
+		}
+		return w.Flush()
+	},
+}
+
+var testKeyEndpoint string
+
+var testKeyCmd = &cobra.Command{
+	Use:   "test <provider>",
+	Short: "Check that a provider's API key works",
+	Long: `Make the smallest possible request to the provider with its API key and
+configured model, and report whether the key was rejected, is out of quota or
+rate limited, or can't use the model.
+
+Use --endpoint to send the request somewhere else, such as a local stub.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider := strings.ToLower(args[0])
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+		if err := validateAPIKeyProvider(cfg, provider); err != nil {
+			return err
+		}
+
+		keyManager := config.NewAPIKeyManager(cfg)
+		apiKey, store, err := keyManager.LookupAPIKey(provider)
+		if err != nil {
+			return err
+		}
+
+		// Keys for endpoints are tried against the first endpoint using them
+		target := provider
+		if !config.IsBuiltinProvider(provider) {
+			target = endpointUsingKey(cfg, provider)
+		}
+		selected := *cfg
+		selected.Provider = target
+		model := selected.ActiveModel()
+		if target == provider {
+			fmt.Printf("Testing %s key %s from %s with model %s...\n", provider, maskAPIKey(apiKey), store, model)
+		} else {
+			fmt.Printf("Testing %s key %s from %s with endpoint %s and model %s...\n", provider, maskAPIKey(apiKey), store, target, model)
+		}
+
+		if err := llm.CheckAPIKey(cmd.Context(), cfg, target, apiKey, testKeyEndpoint); err != nil {
+			var checkErr *llm.KeyCheckError
+			if errors.As(err, &checkErr) {
+				if hint := keyProblemHints[checkErr.Kind]; hint != "" {
+					return fmt.Errorf("%v\n%s", err, hint)
+				}
+			}
+			return err
+		}
+
+		if err := keyManager.RecordVerified(provider, apiKey, time.Now()); err != nil {
+			return err
+		}
+		fmt.Println("OK: the key works")
+		return nil
+	},
+}
+
+// keyProblemHints suggests what to do about each kind of key check failure
+var keyProblemHints = map[string]string{
+	llm.KeyProblemAuth:  "The key was rejected. Check it was copied in full, or create a new one and run 'yts apikey set'.",
+	llm.KeyProblemQuota: "The key works, but the account is rate limited or out of credit. Check the provider's billing and usage limits.",
+	llm.KeyProblemModel: "The key works, but can't use the configured model. Check the model name with 'yts config view'.",
+}
+
 func init() {
 	rootCmd.AddCommand(apikeyCmd)
 	apikeyCmd.AddCommand(setKeyCmd)
 	apikeyCmd.AddCommand(deleteKeyCmd)
+	apikeyCmd.AddCommand(statusKeyCmd)
+	apikeyCmd.AddCommand(listKeyCmd)
+	apikeyCmd.AddCommand(testKeyCmd)
+
+	testKeyCmd.Flags().StringVar(&testKeyEndpoint, "endpoint", "", "API URL to send the request to instead of the provider's")
 }
 
-func isValidAPIKeyProvider(provider string) bool {
-	validProviders := map[string]bool{
-		"claude": true,
-		"openai": true,
+// readAPIKey reads an API key from stdin, asking for it without echoing
+// when stdin is a terminal
+func readAPIKey(provider string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "API key for %s: ", provider)
+		apiKey, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key: %v", err)
+		}
+		return strings.TrimSpace(string(apiKey)), nil
+	}
+
+	apiKey, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key from stdin: %v", err)
+	}
+	return strings.TrimSpace(string(apiKey)), nil
+}
+
+// maskAPIKey shows just enough of a key to tell keys apart
+func maskAPIKey(apiKey string) string {
+	if len(apiKey) < 16 {
+		return strings.Repeat("*", len(apiKey))
+	}
+	return apiKey[:7] + "..." + apiKey[len(apiKey)-4:]
+}
+
+// apiKeyProviders lists the providers that need an API key, followed by the
+// API keys endpoints refer to
+func apiKeyProviders(cfg *config.Config) []string {
+	providers := []string{"claude", "openai", "gemini"}
+	for _, ref := range cfg.APIKeyRefs() {
+		if !config.IsBuiltinProvider(ref) {
+			providers = append(providers, ref)
+		}
+	}
+	return providers
+}
+
+func validateAPIKeyProvider(cfg *config.Config, provider string) error {
+	for _, valid := range apiKeyProviders(cfg) {
+		if provider == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid provider: %s. Valid providers: %s", provider, strings.Join(apiKeyProviders(cfg), ", "))
+}
+
+// endpointUsingKey returns the first endpoint, by name, whose api_key_ref is ref
+func endpointUsingKey(cfg *config.Config, ref string) string {
+	for _, name := range cfg.ProviderNames()[len(config.BuiltinProviders):] {
+		if endpoint, _ := cfg.Endpoint(name); endpoint.APIKeyRef == ref {
+			return name
+		}
 	}
-	return validProviders[provider]
+	return ""
 }
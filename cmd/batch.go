@@ -0,0 +1,535 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+// batchManifestFile records completed videos in the output directory so
+// that --resume can skip them
+const batchManifestFile = ".yts-batch.json"
+
+var (
+	batchInput          string
+	batchOutputDir      string
+	batchFilenameFormat string
+	batchWorkers        int
+	batchLLMConcurrency int
+	batchResume         bool
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Summarize many videos from a file of URLs",
+	Long: `Summarize every video listed in a file, one URL per line. Use "-" to read
+URLs from stdin. Blank lines and lines starting with # are ignored.
+
+Transcripts are fetched concurrently by --workers workers. Requests to the LLM
+are limited separately by --llm-concurrency, which defaults to 1 for local
+providers (lmstudio, ollama) and 4 for hosted ones and endpoints.
+
+Each summary is written to its own file in --output-dir. The filename is a
+Go template with the fields .Index, .VideoID and .Title, for example:
+
+  yts batch -i urls.txt -d digest --filename "{{.Index}} {{.Title}}.md"
+
+A failed video doesn't stop the batch. With --resume, videos that completed
+in an earlier run into the same directory are skipped.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		urls, err := readBatchInput(batchInput)
+		if err != nil {
+			return err
+		}
+		if len(urls) == 0 {
+			return fmt.Errorf("no URLs found in %s", batchInput)
+		}
+
+		client, err := newBatchClient(cfg)
+		if err != nil {
+			return err
+		}
+
+		b, err := newBatchRun(client, batchOutputDir, batchFilenameFormat)
+		if err != nil {
+			return err
+		}
+
+		urls, failed := expandPlaylists(ctx, client, urls)
+
+		// Playlists that couldn't be expanded are listed after the videos
+		results := b.run(ctx, urls)
+		for _, r := range failed {
+			r.Index = len(results) + 1
+			results = append(results, r)
+		}
+
+		printBatchResults(os.Stdout, results)
+		return batchError(ctx, results)
+	},
+}
+
+// Batch item statuses
+const (
+	batchDone    = "done"
+	batchSkipped = "skipped"
+	batchFailed  = "failed"
+)
+
+// batchResult is the outcome of one video in a batch
+type batchResult struct {
+	Index   int
+	URL     string
+	VideoID string
+	Title   string
+	Status  string
+	Output  string
+	Err     error
+}
+
+// batchRun holds the shared state of a running batch
+type batchRun struct {
+	client       *yts.Client
+	outputDir    string
+	nameTemplate *template.Template
+	manifest     *batchManifest // nil when the caller tracks progress itself
+	total        int
+
+	mu       sync.Mutex
+	finished int
+}
+
+// newBatchClient creates a client whose LLM requests are limited by
+// --llm-concurrency, failing early if the provider can't be initialized
+func newBatchClient(cfg *config.Config) (*yts.Client, error) {
+	concurrency := batchLLMConcurrency
+	if concurrency <= 0 {
+		concurrency = yts.DefaultConcurrency(cfg.Provider)
+	}
+
+	client, err := newClient(cfg, yts.WithMaxConcurrentRequests(concurrency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %v", err)
+	}
+	if _, err := client.Provider(); err != nil {
+		return nil, err
+	}
+	if query == "" {
+		if err := client.ValidateStyle(getSummaryType()); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// newBatchRun prepares the output directory and manifest for a batch.
+// filenameFormat is a template for each output filename.
+func newBatchRun(client *yts.Client, outputDir string, filenameFormat string) (*batchRun, error) {
+	nameTemplate, err := template.New("filename").Option("missingkey=error").Parse(filenameFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filename template: %v", err)
+	}
+
+	outputDir, err = expandHome(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	// The manifest records absolute paths so --resume works from any directory
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %v", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	manifest, err := loadBatchManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchRun{
+		client:       client,
+		outputDir:    outputDir,
+		nameTemplate: nameTemplate,
+		manifest:     manifest,
+	}, nil
+}
+
+// run processes urls with a pool of workers and returns the results in
+// input order. Progress is counted afresh on each call, as watch reuses the run.
+func (b *batchRun) run(ctx context.Context, urls []string) []*batchResult {
+	b.total = len(urls)
+	b.finished = 0
+	results := make([]*batchResult, len(urls))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(batchWorkers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = b.process(ctx, i+1, urls[i])
+				b.report(results[i])
+			}
+		}()
+	}
+
+	for i := range urls {
+		if ctx.Err() != nil {
+			results[i] = &batchResult{Index: i + 1, URL: urls[i], Status: batchFailed, Err: ctx.Err()}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// process fetches and summarizes a single video and writes its output file
+func (b *batchRun) process(ctx context.Context, index int, url string) *batchResult {
+	result := &batchResult{Index: index, URL: url, Status: batchFailed}
+
+	videoID, err := yts.ExtractVideoID(url)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.VideoID = videoID
+
+	if batchResume && b.manifest != nil {
+		if entry, ok := b.manifest.completed(videoID); ok {
+			result.Title = entry.Title
+			result.Output = entry.Output
+			result.Status = batchSkipped
+			return result
+		}
+	}
+
+	fetched, err := b.client.Transcript(ctx, url, requestOptions()...)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to fetch transcript: %w", err)
+		return result
+	}
+	result.Title = fetched.Title
+
+	var response string
+	if query != "" {
+		response, err = b.client.AskTranscript(ctx, fetched, query)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to generate query: %w", err)
+			return result
+		}
+	} else {
+		response, err = b.client.SummarizeTranscript(ctx, fetched, yts.Style(getSummaryType()))
+		if err != nil {
+			result.Err = fmt.Errorf("failed to generate summary: %w", err)
+			return result
+		}
+	}
+	response += "\n"
+
+	var name strings.Builder
+	err = b.nameTemplate.Execute(&name, struct {
+		Index   string
+		VideoID string
+		Title   string
+	}{
+		Index:   fmt.Sprintf("%0*d", len(fmt.Sprint(b.total)), index),
+		VideoID: videoID,
+		Title:   sanitizeFilename(fetched.Title),
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("failed to build filename: %w", err)
+		return result
+	}
+
+	output := formatOutput(fetched.Title, fetched.TranslationNote(), query, response)
+	savedPath, err := writeOutputFile(filepath.Join(b.outputDir, name.String()), output)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Output = savedPath
+	result.Status = batchDone
+
+	if b.manifest != nil {
+		if err := b.manifest.record(b.outputDir, videoID, fetched.Title, savedPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update batch manifest: %v\n", err)
+		}
+	}
+	return result
+}
+
+// report prints a progress line as each video finishes
+func (b *batchRun) report(r *batchResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.finished++
+
+	label := r.Title
+	if label == "" {
+		label = r.URL
+	}
+	if r.Err != nil {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s %s: %v\n", b.finished, b.total, r.Status, label, r.Err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d/%d] %s %s\n", b.finished, b.total, r.Status, label)
+}
+
+// printBatchResults prints the final success/failure table
+func printBatchResults(w io.Writer, results []*batchResult) {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tVIDEO\tSTATUS\tRESULT")
+
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+
+		video := r.VideoID
+		if video == "" {
+			video = r.URL
+		}
+		detail := r.Output
+		if r.Err != nil {
+			detail = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", r.Index, video, r.Status, truncate(detail, 80))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d done, %d skipped, %d failed\n", counts[batchDone], counts[batchSkipped], counts[batchFailed])
+}
+
+// batchError returns the error a batch command should exit with, if any
+func batchError(ctx context.Context, results []*batchResult) error {
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == batchFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d videos failed", failed, len(results))
+	}
+	return nil
+}
+
+// readBatchInput reads URLs from a file, or stdin when path is "-". Blank
+// lines, comments and repeated videos are skipped.
+func readBatchInput(path string) ([]string, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		expanded, err := expandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input file: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var urls []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if videoID, err := yts.ExtractVideoID(line); err == nil {
+			if seen[videoID] {
+				continue
+			}
+			seen[videoID] = true
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %v", err)
+	}
+	return urls, nil
+}
+
+// expandPlaylists replaces playlist URLs in urls with the URLs of their
+// videos, skipping videos already listed. Playlists that can't be fetched
+// are returned as failed results rather than stopping the batch.
+func expandPlaylists(ctx context.Context, client *yts.Client, urls []string) ([]string, []*batchResult) {
+	var expanded []string
+	var failed []*batchResult
+	seen := map[string]bool{}
+	add := func(url string) {
+		if videoID, err := yts.ExtractVideoID(url); err == nil {
+			if seen[videoID] {
+				return
+			}
+			seen[videoID] = true
+		}
+		expanded = append(expanded, url)
+	}
+
+	for _, url := range urls {
+		if !yts.IsPlaylistURL(url) {
+			add(url)
+			continue
+		}
+
+		playlist, err := client.Playlist(ctx, url)
+		if err != nil {
+			err = fmt.Errorf("failed to expand playlist: %w", err)
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", url, err)
+			failed = append(failed, &batchResult{URL: url, Status: batchFailed, Err: err})
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Expanded playlist %q to %d videos\n", playlist.Title, len(playlist.Videos))
+		for _, v := range playlist.Videos {
+			add(v.URL())
+		}
+	}
+	return expanded, failed
+}
+
+// sanitizeFilename makes a video title safe to use in a filename
+func sanitizeFilename(name string) string {
+	const maxLen = 100
+
+	var out strings.Builder
+	for _, r := range name {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			out.WriteRune('_')
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	result := strings.Trim(out.String(), " .")
+	if runes := []rune(result); len(runes) > maxLen {
+		result = strings.TrimSpace(string(runes[:maxLen]))
+	}
+	if result == "" {
+		return "untitled"
+	}
+	return result
+}
+
+// batchManifestEntry records a completed video
+type batchManifestEntry struct {
+	Title       string    `json:"title"`
+	Output      string    `json:"output"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// batchManifest tracks completed videos in an output directory. It is
+// saved after every video so an interrupted batch can be resumed.
+type batchManifest struct {
+	mu      sync.Mutex
+	Entries map[string]*batchManifestEntry `json:"videos"`
+}
+
+func loadBatchManifest(dir string) (*batchManifest, error) {
+	m := &batchManifest{Entries: map[string]*batchManifestEntry{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, batchManifestFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch manifest: %v", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse batch manifest: %v", err)
+	}
+	if m.Entries == nil {
+		m.Entries = map[string]*batchManifestEntry{}
+	}
+	return m, nil
+}
+
+// completed returns the entry for a video if it finished in an earlier run
+// and its output file still exists
+func (m *batchManifest) completed(videoID string) (*batchManifestEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.Entries[videoID]
+	if !ok {
+		return nil, false
+	}
+	if _, err := os.Stat(entry.Output); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// record marks a video as completed and saves the manifest
+func (m *batchManifest) record(dir string, videoID string, title string, output string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Entries[videoID] = &batchManifestEntry{
+		Title:       title,
+		Output:      output,
+		CompletedAt: time.Now(),
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, batchManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().StringVarP(&batchInput, "input", "i", "", `file with one URL per line, or "-" for stdin`)
+	batchCmd.Flags().StringVarP(&batchOutputDir, "output-dir", "d", ".", "directory to write summaries to")
+	batchCmd.Flags().StringVar(&batchFilenameFormat, "filename", "{{.Index}} - {{.Title}}.md", "output filename template (fields: .Index, .VideoID, .Title)")
+	batchCmd.Flags().IntVarP(&batchWorkers, "workers", "w", 4, "number of videos to process at once")
+	batchCmd.Flags().IntVar(&batchLLMConcurrency, "llm-concurrency", 0, "maximum concurrent LLM requests (default 1 for local providers, 4 otherwise)")
+	batchCmd.Flags().BoolVar(&batchResume, "resume", false, "skip videos already completed in the output directory")
+	addStyleFlags(batchCmd)
+	batchCmd.Flags().StringVarP(&query, "query", "q", "", "Ask the same question about every video")
+	addFetchFlags(batchCmd)
+	batchCmd.MarkFlagRequired("input")
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the transcript and response caches",
+	Long: `Manage cached transcripts and LLM responses. Fetched transcripts are cached
+per video and language selection so repeated runs don't hit YouTube again.
+LLM responses are cached when cache.responses is enabled.
+Available subcommands:
+  - ls:    List cached transcripts
+  - stats: Show cache sizes and response cache hit rate
+  - clear: Remove all cached transcripts and responses
+  - prune: Remove expired cached transcripts`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cached transcripts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := transcriptStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		entries, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("The transcript cache is empty.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VIDEO ID\tLANGUAGES\tTITLE\tAGE\tSIZE\tSTATUS")
+		for _, entry := range entries {
+			status := "fresh"
+			if store.Expired(entry) {
+				status = "expired"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				entry.VideoID,
+				entry.Key,
+				truncate(entry.Transcript.Title, 50),
+				formatAge(time.Since(entry.FetchedAt)),
+				formatSize(entry.Size),
+				status,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache sizes and response cache hit rate",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := transcriptStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		entries, err := store.List()
+		if err != nil {
+			return err
+		}
+		var transcriptSize int64
+		expired := 0
+		for _, entry := range entries {
+			transcriptSize += entry.Size
+			if store.Expired(entry) {
+				expired++
+			}
+		}
+
+		responses, err := responseStore()
+		if err != nil {
+			return err
+		}
+		responseCount, responseSize, err := responses.Usage()
+		if err != nil {
+			return err
+		}
+		stats := responses.Stats()
+
+		fmt.Println("Transcripts")
+		fmt.Printf("├── Entries: %d (%d expired)\n", len(entries), expired)
+		fmt.Printf("└── Size: %s\n", formatSize(transcriptSize))
+		fmt.Println("Responses")
+		fmt.Printf("├── Entries: %d\n", responseCount)
+		fmt.Printf("├── Size: %s\n", formatSize(responseSize))
+		fmt.Printf("├── Hits: %d\n", stats.Hits)
+		fmt.Printf("├── Misses: %d\n", stats.Misses)
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			fmt.Printf("└── Hit Rate: %.1f%%\n", 100*float64(stats.Hits)/float64(lookups))
+		} else {
+			fmt.Println("└── Hit Rate: n/a")
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached transcripts and responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := transcriptStore(cmd)
+		if err != nil {
+			return err
+		}
+		if err := store.Clear(); err != nil {
+			return err
+		}
+
+		responses, err := responseStore()
+		if err != nil {
+			return err
+		}
+		if err := responses.Clear(); err != nil {
+			return err
+		}
+
+		fmt.Println("Cache cleared.")
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired cached transcripts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := transcriptStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		removed, err := store.Prune()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d expired transcript(s).\n", removed)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+}
+
+// transcriptStore opens the on-disk transcript cache with the configured TTL
+func transcriptStore(cmd *cobra.Command) (*cache.TranscriptStore, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %v", err)
+	}
+
+	cacheDir, err := cache.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(cfg.Cache.TranscriptTTLHours) * time.Hour
+	return cache.NewTranscriptStore(cacheDir, ttl), nil
+}
+
+// responseStore opens the on-disk LLM response cache
+func responseStore() (*cache.ResponseStore, error) {
+	cacheDir, err := cache.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewResponseStore(cacheDir), nil
+}
+
+// truncate shortens s to at most n runes, adding an ellipsis when cut
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// formatAge renders a duration in the largest sensible unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// formatSize renders a byte count in KB or MB
+func formatSize(bytes int64) string {
+	if bytes < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+const chatHelp = `Commands:
+  /summary [style]   Summarize the video (short, long or a configured style)
+  /save <file>       Save the conversation to a file
+  /reset             Clear the conversation history
+  /provider <name>   Switch to another LLM provider
+  /help              Show this help
+  /exit              Leave the chat`
+
+var chatCmd = &cobra.Command{
+	Use:   "chat [youtube-url]",
+	Short: "Chat interactively about a video",
+	Long: `Start an interactive chat about a video. The transcript is fetched once
+and kept for the whole session, so follow-up questions don't re-fetch it.
+
+` + chatHelp,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		client, err := newClient(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize client: %v", err)
+		}
+		if _, err := client.Provider(); err != nil {
+			return err
+		}
+
+		result, err := client.Transcript(ctx, args[0], requestOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transcript: %v", err)
+		}
+
+		fmt.Printf("\nTitle: %s\n", result.Title)
+		if note := result.TranslationNote(); note != "" {
+			fmt.Printf("Translation: %s\n", note)
+		}
+		fmt.Printf("Provider: %s\n\nType /help for commands.\n\n", cfg.Provider)
+
+		session := &chatSession{
+			ctx:        ctx,
+			client:     client,
+			transcript: result,
+		}
+		return session.run(os.Stdin)
+	},
+}
+
+// chatSession holds the state of an interactive chat about one video
+type chatSession struct {
+	ctx        context.Context
+	client     *yts.Client
+	transcript *yts.Transcript
+	history    []yts.Message
+}
+
+// run reads lines from in until EOF, /exit or interruption, dispatching slash
+// commands and sending everything else to the provider as a question
+func (s *chatSession) run(in io.Reader) error {
+	// Read input in the background so Ctrl-C at the prompt ends the session
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				lines <- line
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		fmt.Print("> ")
+
+		var line string
+		select {
+		case <-s.ctx.Done():
+			fmt.Println()
+			return errInterrupted
+		case err := <-readErr:
+			fmt.Println()
+			if err != io.EOF {
+				return fmt.Errorf("failed to read input: %v", err)
+			}
+			return nil
+		case line = <-lines:
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			if done := s.handleCommand(line); done {
+				return nil
+			}
+			continue
+		}
+
+		s.ask(line)
+	}
+}
+
+// ask sends a user message and streams the reply. Failed turns are dropped
+// from the history so the conversation stays consistent.
+func (s *chatSession) ask(content string) {
+	s.history = append(s.history, yts.Message{Role: yts.RoleUser, Content: content})
+
+	reply, err := s.client.Chat(s.ctx, s.transcript, s.history, yts.OnText(func(chunk string) {
+		fmt.Print(chunk)
+	}))
+	if s.ctx.Err() != nil {
+		fmt.Print("\n\n[interrupted]\n")
+		return
+	}
+	fmt.Print("\n\n")
+	if err != nil {
+		s.history = s.history[:len(s.history)-1]
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		return
+	}
+
+	s.history = append(s.history, yts.Message{Role: yts.RoleAssistant, Content: reply})
+}
+
+// handleCommand runs a slash command and reports whether the session should end
+func (s *chatSession) handleCommand(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/exit", "/quit":
+		return true
+	case "/help":
+		fmt.Printf("%s\n\n", chatHelp)
+	case "/summary":
+		prompt, err := s.client.SummaryPrompt(s.transcript, arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+			break
+		}
+		s.ask(prompt)
+	case "/save":
+		if arg == "" {
+			fmt.Fprint(os.Stderr, "Usage: /save <file>\n\n")
+			break
+		}
+		savedPath, err := writeOutputFile(arg, s.conversation())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+			break
+		}
+		fmt.Printf("Conversation saved to %s\n\n", savedPath)
+	case "/reset":
+		s.history = nil
+		fmt.Print("Conversation history cleared.\n\n")
+	case "/provider":
+		if arg == "" {
+			fmt.Printf("Current provider: %s\n\n", s.client.Config().Provider)
+			break
+		}
+		if err := validateProvider(s.client.Config(), arg); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n\n", err)
+			break
+		}
+		client, err := newClient(s.client.Config(), yts.WithProviderName(arg))
+		if err == nil {
+			_, err = client.Provider()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+			break
+		}
+		s.client = client
+		fmt.Printf("Switched to %s.\n\n", arg)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s\n\n", name, chatHelp)
+	}
+
+	return false
+}
+
+// conversation renders the chat history as plain text
+func (s *chatSession) conversation() string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Title: %s\n\n", s.transcript.Title))
+	for _, m := range s.history {
+		speaker := "You"
+		if m.Role == yts.RoleAssistant {
+			speaker = "Assistant"
+		}
+		out.WriteString(fmt.Sprintf("%s: %s\n\n", speaker, m.Content))
+	}
+	return out.String()
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+	addFetchFlags(chatCmd)
+}
@@ -9,9 +9,12 @@ var configCmd = &cobra.Command{
 	Short: "Manage YTS configuration",
 	Long: `Manage YTS CLI configuration settings.
 Available subcommands:
-  - view:  Display current configuration
-  - set:   Set a configuration value
-  - edit:  Edit configuration in your default text editor`,
+  - view:     Display current configuration
+  - set:      Set a configuration value
+  - edit:     Edit configuration in your default text editor
+  - migrate:  Upgrade the configuration file to the current version
+  - profile:  Manage configuration profiles
+  - endpoint: Manage OpenAI-compatible endpoints`,
 }
 
 func init() {
@@ -21,4 +24,7 @@ func init() {
 	configCmd.AddCommand(viewCmd)
 	configCmd.AddCommand(setCmd)
 	configCmd.AddCommand(editCmd)
+	configCmd.AddCommand(migrateCmd)
+	configCmd.AddCommand(profileCmd)
+	configCmd.AddCommand(endpointCmd)
 }
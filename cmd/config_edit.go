@@ -4,30 +4,24 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var editCmd = &cobra.Command{
 	Use:   "edit",
 	Short: "Edit configuration file in your default text editor",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configDir, err := os.UserConfigDir()
+		loader := configLoader(cmd)
+		configPath, err := loader.Path()
 		if err != nil {
-			return fmt.Errorf("failed to get config directory: %v", err)
+			return err
 		}
 
-		configPath := filepath.Join(configDir, "yts", "config.json")
-
-		// Ensure config file exists
-		if !fileExists(configPath) {
-			// Initialize with defaults if it doesn't exist
-			if err := initializeConfig(configPath); err != nil {
-				return fmt.Errorf("failed to initialize config: %v", err)
-			}
+		// Loading creates the config file with defaults if it doesn't exist
+		if _, err := loader.Load(); err != nil {
+			return fmt.Errorf("failed to initialize config: %v", err)
 		}
 
 		editor := getEditor()
@@ -71,21 +65,6 @@ func getEditor() string {
 	return ""
 }
 
-func initializeConfig(path string) error {
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %v", err)
-	}
-
-	// Write default config if it doesn't exist
-	if err := viper.SafeWriteConfigAs(path); err != nil {
-		return fmt.Errorf("failed to write default config: %v", err)
-	}
-
-	return nil
-}
-
 // Helper function used by multiple commands
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
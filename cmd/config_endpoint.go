@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	endpointBaseURL     string
+	endpointModel       string
+	endpointAPIKeyRef   string
+	endpointTemperature float64
+)
+
+var endpointCmd = &cobra.Command{
+	Use:   "endpoint",
+	Short: "Manage OpenAI-compatible endpoints",
+	Long: `Manage extra providers that speak the OpenAI Chat Completions API, such as
+vLLM, llama.cpp server, Groq, Together, OpenRouter and LocalAI. Each endpoint
+is used like a built-in provider, e.g. 'yts -p groq <url>'.
+Available subcommands:
+  - list:   List endpoints
+  - create: Create an endpoint
+  - delete: Delete an endpoint
+
+Other endpoint settings are set like any other, e.g.
+  yts config set endpoints.openrouter.headers.x-title yts
+  yts config set endpoints.groq.max_tokens 4096`,
+}
+
+var endpointListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List endpoints",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		names := cfg.ProviderNames()[len(config.BuiltinProviders):]
+		if len(names) == 0 {
+			fmt.Println("No endpoints defined. Create one with 'yts config endpoint create'.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tBASE URL\tMODEL\tAPI KEY")
+		for _, name := range names {
+			endpoint, _ := cfg.Endpoint(name)
+			active := ""
+			if name == cfg.Provider {
+				active = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				active,
+				name,
+				valueOrDash(endpoint.BaseURL),
+				valueOrDash(endpoint.Model),
+				valueOrDash(endpoint.APIKeyRef),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var endpointCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an endpoint",
+	Example: `  yts config endpoint create llamacpp --base-url http://localhost:8080/v1 --model qwen2.5-7b
+  yts config endpoint create groq --base-url https://api.groq.com/openai/v1 \
+    --model llama-3.3-70b-versatile --api-key-ref groq`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if u, err := url.Parse(endpointBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid base URL: %s (expected e.g. http://localhost:8000/v1)", endpointBaseURL)
+		}
+		if endpointAPIKeyRef != "" && !config.EndpointNamePattern.MatchString(endpointAPIKeyRef) {
+			return fmt.Errorf("invalid API key name: %s (use lowercase letters, digits, - and _)", endpointAPIKeyRef)
+		}
+
+		endpoint := config.Endpoint{
+			Type:      config.EndpointTypeOpenAICompatible,
+			BaseURL:   endpointBaseURL,
+			Model:     endpointModel,
+			APIKeyRef: endpointAPIKeyRef,
+		}
+		if cmd.Flags().Changed("temperature") {
+			endpoint.Temperature = &endpointTemperature
+		}
+
+		if err := configLoader(cmd).CreateEndpoint(args[0], endpoint); err != nil {
+			return err
+		}
+		fmt.Printf("Created endpoint %s. Use it with --provider %s or 'yts config set provider %s'.\n", args[0], args[0], args[0])
+		if endpointAPIKeyRef != "" {
+			fmt.Printf("Save its API key with 'yts apikey set %s'.\n", endpointAPIKeyRef)
+		}
+		return nil
+	},
+}
+
+var endpointDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an endpoint",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configLoader(cmd).DeleteEndpoint(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted endpoint %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	endpointCmd.AddCommand(endpointListCmd)
+	endpointCmd.AddCommand(endpointCreateCmd)
+	endpointCmd.AddCommand(endpointDeleteCmd)
+
+	endpointCreateCmd.Flags().StringVar(&endpointBaseURL, "base-url", "", "API root, without /chat/completions")
+	endpointCreateCmd.Flags().StringVar(&endpointModel, "model", "", "model to use")
+	endpointCreateCmd.Flags().StringVar(&endpointAPIKeyRef, "api-key-ref", "", "name of the API key saved with 'yts apikey set'")
+	endpointCreateCmd.Flags().Float64Var(&endpointTemperature, "temperature", 0, "generation temperature")
+	endpointCreateCmd.MarkFlagRequired("base-url")
+	endpointCreateCmd.MarkFlagRequired("model")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// maxDiffValueLen truncates long values, such as prompts, in the diff
+const maxDiffValueLen = 70
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration file to the current version",
+	Long: `Upgrade the configuration file to the current version. A timestamped backup
+of the original is saved next to it first. Older config files are also
+migrated automatically the first time any command loads them.
+
+Use --dry-run to see the changes without writing anything.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loader := configLoader(cmd)
+		configPath, err := loader.Path()
+		if err != nil {
+			return err
+		}
+
+		migration, err := loader.Migrate(migrateDryRun)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Config File: %s\n", configPath)
+		if !migration.Needed() {
+			fmt.Printf("Configuration is already at version %s\n", migration.To)
+			return nil
+		}
+		fmt.Printf("Version: %s → %s\n\n", migration.From, migration.To)
+
+		fmt.Println("Steps:")
+		for _, step := range migration.Steps {
+			fmt.Printf("  %s\n", step)
+		}
+
+		fmt.Println("\nChanges:")
+		for _, change := range config.Diff(migration.Before, migration.After) {
+			if change.Old != nil {
+				fmt.Printf("- %s: %s\n", change.Key, diffValue(change.Old))
+			}
+			if change.New != nil {
+				fmt.Printf("+ %s: %s\n", change.Key, diffValue(change.New))
+			}
+		}
+
+		if migrateDryRun {
+			fmt.Println("\nDry run: no changes were written")
+			return nil
+		}
+		fmt.Printf("\nBackup saved to %s\n", migration.Backup)
+		return nil
+	},
+}
+
+// diffValue formats a config value as JSON on a single line
+func diffValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	text := []rune(string(data))
+	if len(text) > maxDiffValueLen {
+		return string(text[:maxDiffValueLen]) + "…"
+	}
+	return string(text)
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "show the changes without writing them")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileProvider    string
+	profileModel       string
+	profileTemperature float64
+	profileNone        bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage configuration profiles",
+	Long: `Manage named profiles that override the provider, model, temperature and
+prompts. Select a profile for one command with --profile or YTS_PROFILE, or
+for every command with 'yts config profile use'.
+Available subcommands:
+  - list:   List profiles
+  - use:    Set the active profile
+  - create: Create a profile
+  - delete: Delete a profile
+
+Profile prompts are set like other settings, e.g.
+  yts config set profiles.cloud.summaries.short.system_prompt "..."`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			fmt.Println("No profiles defined. Create one with 'yts config profile create'.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tPROVIDER\tMODEL\tTEMPERATURE\tPROMPTS")
+		for _, name := range names {
+			profile := cfg.Profiles[name]
+			active := ""
+			if name == cfg.Profile {
+				active = "*"
+			}
+			temperature := "-"
+			if profile.Temperature != nil {
+				temperature = fmt.Sprintf("%.1f", *profile.Temperature)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+				active,
+				name,
+				valueOrDash(profile.Provider),
+				valueOrDash(profile.Model),
+				temperature,
+				countProfilePrompts(profile),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Set the active profile",
+	Long: `Set the profile used by every command unless --profile or YTS_PROFILE
+selects another. Use --none to stop using a profile.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if profileNone == (len(args) == 1) {
+			return fmt.Errorf("give a profile name or --none")
+		}
+
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if err := configLoader(cmd).UseProfile(name); err != nil {
+			return err
+		}
+
+		if name == "" {
+			fmt.Println("No profile is active")
+		} else {
+			fmt.Printf("Now using profile %s\n", name)
+		}
+		return nil
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Example: `  yts config profile create local-fast --provider ollama --model llama3.2
+  yts config profile create cloud --provider claude --temperature 0.2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if profileProvider != "" {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to get config: %v", err)
+			}
+			if err := validateProvider(cfg, profileProvider); err != nil {
+				return err
+			}
+		}
+
+		profile := config.Profile{Provider: profileProvider, Model: profileModel}
+		if cmd.Flags().Changed("temperature") {
+			profile.Temperature = &profileTemperature
+		}
+
+		if err := configLoader(cmd).CreateProfile(args[0], profile); err != nil {
+			return err
+		}
+		fmt.Printf("Created profile %s. Use it with --profile %s or 'yts config profile use %s'.\n", args[0], args[0], args[0])
+		return nil
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := configLoader(cmd).DeleteProfile(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted profile %s\n", args[0])
+		return nil
+	},
+}
+
+// countProfilePrompts returns the number of prompts a profile overrides
+func countProfilePrompts(p config.Profile) int {
+	count := len(p.Summaries)
+	for _, prompt := range []string{p.Transcripts.SystemPrompt, p.Queries.SystemPrompt, p.Chat.SystemPrompt} {
+		if prompt != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+
+	profileUseCmd.Flags().BoolVar(&profileNone, "none", false, "stop using a profile")
+
+	profileCreateCmd.Flags().StringVar(&profileProvider, "provider", "", "provider to use (lmstudio, ollama, claude, openai, gemini or an endpoint)")
+	profileCreateCmd.Flags().StringVar(&profileModel, "model", "", "model to use with the provider")
+	profileCreateCmd.Flags().Float64Var(&profileTemperature, "temperature", 0, "generation temperature")
+}
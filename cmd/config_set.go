@@ -2,85 +2,277 @@ package cmd
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/internal/prompt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var validPaths = map[string]struct{}{
 	// Global
 	"provider": {},
 
+	// Prompts
+	"chat.system_prompt":        {},
+	"queries.system_prompt":     {},
+	"transcripts.system_prompt": {},
+
+	// Cache
+	"cache.transcript_ttl_hours": {},
+	"cache.responses":            {},
+
+	// Secrets
+	"secrets.order": {},
+	"secrets.store": {},
+
+	// Watch
+	"watch.feed_base_url":    {},
+	"watch.channels":         {},
+	"watch.interval_minutes": {},
+	"watch.output_dir":       {},
+
 	// LM Studio
-	"providers.lmstudio.base_url": {},
-	"providers.lmstudio.model":    {},
+	"providers.lmstudio.base_url":                {},
+	"providers.lmstudio.model":                   {},
+	"providers.lmstudio.temperature":             {},
+	"providers.lmstudio.chunking.chunk_tokens":   {},
+	"providers.lmstudio.chunking.overlap_tokens": {},
+	"providers.lmstudio.chunking.reduce_prompt":  {},
 
 	// Ollama
-	"providers.ollama.base_url": {},
-	"providers.ollama.model":    {},
+	"providers.ollama.base_url":                {},
+	"providers.ollama.model":                   {},
+	"providers.ollama.temperature":             {},
+	"providers.ollama.chunking.chunk_tokens":   {},
+	"providers.ollama.chunking.overlap_tokens": {},
+	"providers.ollama.chunking.reduce_prompt":  {},
 
 	// Claude
-	"providers.claude.model":           {},
-	"providers.claude.temperature":     {},
-	"providers.claude.max_tokens":      {},
-	"providers.claude.timeout_seconds": {},
-	"providers.claude.max_retries":     {},
+	"providers.claude.base_url":                {},
+	"providers.claude.model":                   {},
+	"providers.claude.temperature":             {},
+	"providers.claude.max_tokens":              {},
+	"providers.claude.timeout_seconds":         {},
+	"providers.claude.max_retries":             {},
+	"providers.claude.chunking.chunk_tokens":   {},
+	"providers.claude.chunking.overlap_tokens": {},
+	"providers.claude.chunking.reduce_prompt":  {},
 
 	// OpenAI
-	"providers.openai.model":           {},
-	"providers.openai.temperature":     {},
-	"providers.openai.max_tokens":      {},
-	"providers.openai.timeout_seconds": {},
-	"providers.openai.max_retries":     {},
-	"providers.openai.organization_id": {},
+	"providers.openai.base_url":                {},
+	"providers.openai.model":                   {},
+	"providers.openai.temperature":             {},
+	"providers.openai.max_tokens":              {},
+	"providers.openai.timeout_seconds":         {},
+	"providers.openai.max_retries":             {},
+	"providers.openai.organization_id":         {},
+	"providers.openai.chunking.chunk_tokens":   {},
+	"providers.openai.chunking.overlap_tokens": {},
+	"providers.openai.chunking.reduce_prompt":  {},
+
+	// Gemini
+	"providers.gemini.base_url":                {},
+	"providers.gemini.model":                   {},
+	"providers.gemini.temperature":             {},
+	"providers.gemini.max_output_tokens":       {},
+	"providers.gemini.timeout_seconds":         {},
+	"providers.gemini.max_retries":             {},
+	"providers.gemini.chunking.chunk_tokens":   {},
+	"providers.gemini.chunking.overlap_tokens": {},
+	"providers.gemini.chunking.reduce_prompt":  {},
 }
 
+// styleKeyPattern matches the settings of a named summary style, e.g.
+// summaries.meeting-notes.system_prompt
+var styleKeyPattern = regexp.MustCompile(`^summaries\.([a-z0-9_-]+)\.(system_prompt|provider|model|temperature)$`)
+
+// profileKeyPattern matches the settings of a named profile, e.g.
+// profiles.cloud.model or profiles.cloud.summaries.short.system_prompt
+var profileKeyPattern = regexp.MustCompile(`^profiles\.([a-z0-9_-]+)\.(.+)$`)
+
+// headerKeyPattern matches a static header sent to a hosted provider, e.g.
+// providers.claude.headers.x-gateway-key
+var headerKeyPattern = regexp.MustCompile(`^providers\.(claude|openai)\.headers\.[a-z0-9-]+$`)
+
+// endpointKeyPattern matches the settings of a named endpoint, e.g.
+// endpoints.groq.base_url or endpoints.groq.headers.x-title
+var endpointKeyPattern = regexp.MustCompile(`^endpoints\.([a-z0-9_-]+)\.(type|base_url|model|api_key_ref|temperature|top_p|max_tokens|timeout_seconds|max_retries|chunking\.(chunk_tokens|overlap_tokens|reduce_prompt)|headers\.[a-z0-9-]+)$`)
+
 var setCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
-	Args:  cobra.ExactArgs(2),
+	Long: `Set a configuration value.
+
+Prompts (keys ending in system_prompt or reduce_prompt) are Go templates and
+are checked before saving. They can use:
+
+  {{.Title}}       Video title
+  {{.Query}}       The question being answered (query prompts)
+  {{.Channel}}     Channel name
+  {{.UploadDate}}  Upload date, YYYY-MM-DD
+  {{.Duration}}    Video length, e.g. 12:34
+  {{.Language}}    Language code of the transcript
+  {{.Chapters}}    Chapters, each with .Title and .Start
+  {{.URL}}         Video URL
+  {{.Transcript}}  Transcript text
+  {{today}}        Current date, YYYY-MM-DD
+
+For example:
+  yts config set queries.system_prompt 'Answer "{{.Query}}" about "{{.Title}}" by {{.Channel}}.'`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.ToLower(args[0])
 		value := args[1]
 
 		// Validate key
-		if _, ok := validPaths[key]; !ok {
+		_, ok := validPaths[key]
+		styleKey := styleKeyPattern.FindStringSubmatch(key)
+		profileKey := profileKeyPattern.FindStringSubmatch(key)
+		if profileKey != nil && !config.IsProfileKey(profileKey[2]) {
+			profileKey = nil
+		}
+		isHeaderKey := headerKeyPattern.MatchString(key)
+		endpointKey := endpointKeyPattern.FindStringSubmatch(key)
+		if !ok && styleKey == nil && profileKey == nil && !isHeaderKey && endpointKey == nil {
 			return fmt.Errorf("invalid configuration key: %s\nValid keys: %s",
 				key, strings.Join(getValidKeys(), ", "))
 		}
 
 		// Validate provider if setting provider
-		if key == "provider" && !isValidProvider(value) {
-			return fmt.Errorf("invalid provider: %s\nValid providers: lmstudio, ollama, claude, openai", value)
+		isProviderKey := key == "provider" ||
+			(styleKey != nil && styleKey[2] == "provider") ||
+			(profileKey != nil && profileKey[2] == "provider")
+		if isProviderKey {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to get config: %v", err)
+			}
+			if err := validateProvider(cfg, value); err != nil {
+				return err
+			}
+		}
+
+		// Endpoints can't shadow built-in providers
+		if endpointKey != nil {
+			if config.IsBuiltinProvider(endpointKey[1]) {
+				return fmt.Errorf("%s is a built-in provider; configure it under providers.%s", endpointKey[1], endpointKey[1])
+			}
+			if endpointKey[2] == "type" && value != config.EndpointTypeOpenAICompatible {
+				return fmt.Errorf("invalid endpoint type: %s\nValid types: %s", value, config.EndpointTypeOpenAICompatible)
+			}
+			if endpointKey[2] == "api_key_ref" && value != "" && !config.EndpointNamePattern.MatchString(value) {
+				return fmt.Errorf("invalid API key name: %s (use lowercase letters, digits, - and _)", value)
+			}
 		}
 
-		// Set the value
-		viper.Set(key, value)
+		// Prompts must be valid templates
+		if strings.HasSuffix(key, "system_prompt") || strings.HasSuffix(key, "reduce_prompt") {
+			if err := prompt.Validate(key, value); err != nil {
+				return err
+			}
+		}
 
-		// Save the configuration
-		if err := viper.WriteConfig(); err != nil {
-			return fmt.Errorf("failed to save configuration: %v", err)
+		// Hosted provider and endpoint URLs must be absolute
+		if key == "providers.claude.base_url" || key == "providers.openai.base_url" || key == "providers.gemini.base_url" ||
+			(endpointKey != nil && endpointKey[2] == "base_url") {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid base URL: %s (expected e.g. https://gateway.example.com)", value)
+			}
 		}
 
-		return nil
+		// Secret stores must be ones yts knows about
+		if key == "secrets.order" || key == "secrets.store" {
+			stores := splitList(value)
+			if len(stores) == 0 {
+				return fmt.Errorf("%s needs at least one secret store", key)
+			}
+			if key == "secrets.store" && len(stores) > 1 {
+				return fmt.Errorf("secrets.store takes a single secret store")
+			}
+			for _, store := range stores {
+				if !isValidSecretStore(store, key == "secrets.store") {
+					return fmt.Errorf("invalid secret store: %s\nValid stores: %s", store, validSecretStores(key == "secrets.store"))
+				}
+			}
+			if key == "secrets.order" {
+				return configLoader(cmd).Set(key, stores)
+			}
+			return configLoader(cmd).Set(key, stores[0])
+		}
+
+		// Sampling parameters are stored as numbers, since some can be left unset
+		if strings.HasSuffix(key, ".temperature") || strings.HasSuffix(key, ".top_p") {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %s", key[strings.LastIndex(key, ".")+1:], value)
+			}
+			return configLoader(cmd).Set(key, number)
+		}
+
+		// Set the value and save it. Lists are given comma-separated.
+		if key == "watch.channels" {
+			return configLoader(cmd).Set(key, splitList(value))
+		}
+		return configLoader(cmd).Set(key, value)
 	},
 }
 
-func isValidProvider(provider string) bool {
-	validProviders := map[string]bool{
-		"lmstudio": true,
-		"ollama":   true,
-		"claude":   true,
-		"openai":   true,
+// validateProvider checks that provider is built in or a configured endpoint
+func validateProvider(cfg *config.Config, provider string) error {
+	if !cfg.IsProvider(provider) {
+		return fmt.Errorf("invalid provider: %s\nValid providers: %s", provider, strings.Join(cfg.ProviderNames(), ", "))
+	}
+	return nil
+}
+
+// isValidSecretStore reports whether store can be used, and for writing
+// keys if writable is set
+func isValidSecretStore(store string, writable bool) bool {
+	switch store {
+	case config.SecretStoreKeyring, config.SecretStoreFile:
+		return true
+	case config.SecretStoreEnv:
+		return !writable
 	}
-	return validProviders[provider]
+	return false
+}
+
+func validSecretStores(writable bool) string {
+	if writable {
+		return "keyring, file"
+	}
+	return "env, keyring, file"
 }
 
 func getValidKeys() []string {
-	keys := make([]string, 0, len(validPaths))
+	keys := make([]string, 0, len(validPaths)+1)
 	for k := range validPaths {
 		keys = append(keys, k)
 	}
-	return keys
+	sort.Strings(keys)
+	return append(keys,
+		"summaries.<style>.{system_prompt,provider,model,temperature}",
+		"profiles.<profile>.{provider,model,temperature}",
+		"profiles.<profile>.{summaries.<style>,transcripts,queries,chat}.system_prompt",
+		"providers.{claude,openai}.headers.<name>",
+		"endpoints.<endpoint>.{type,base_url,model,api_key_ref,temperature,top_p,max_tokens,timeout_seconds,max_retries}",
+		"endpoints.<endpoint>.chunking.{chunk_tokens,overlap_tokens,reduce_prompt}",
+		"endpoints.<endpoint>.headers.<name>",
+	)
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
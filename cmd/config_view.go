@@ -2,8 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/conormkelly/yts-cli/internal/config"
@@ -13,20 +12,27 @@ import (
 var viewCmd = &cobra.Command{
 	Use:   "view",
 	Short: "View current configuration",
+	Long: `View the effective configuration, after applying the active profile,
+environment variables and flags. Each setting shows where its value came
+from: default, config file, profile <name>, env <VAR> or flag --<name>.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.GetConfig()
+		loader := configLoader(cmd)
+		cfg, sources, err := loader.LoadWithSources()
 		if err != nil {
 			return fmt.Errorf("failed to get config: %v", err)
 		}
 
-		configDir, err := os.UserConfigDir()
+		configPath, err := loader.Path()
 		if err != nil {
-			return fmt.Errorf("failed to get config directory: %v", err)
+			return err
 		}
-		configPath := filepath.Join(configDir, "yts", "config.json")
 
-		keyManager := config.NewAPIKeyManager()
-		isClaudeAPIKeySet := keyManager.HasAPIKey("claude")
+		keyManager := config.NewAPIKeyManager(cfg)
+
+		// from describes where a setting's value came from
+		from := func(key string) string {
+			return "  [" + sources.Of(key) + "]"
+		}
 
 		// Print header with nice formatting
 		fmt.Printf("YTS Configuration\n%s\n\n", strings.Repeat("=", 17))
@@ -40,36 +46,192 @@ var viewCmd = &cobra.Command{
 		}
 		fmt.Println()
 
-		// Provider settings
-		fmt.Printf("Active Provider: %s\n", cfg.Provider)
+		// Profile and provider settings
+		if cfg.Profile != "" {
+			fmt.Printf("Active Profile: %s%s\n", cfg.Profile, from("profile"))
+		} else {
+			fmt.Println("Active Profile: none")
+		}
+		if names := cfg.ProfileNames(); len(names) > 0 {
+			fmt.Printf("Profiles: %s\n", strings.Join(names, ", "))
+		}
+		fmt.Printf("Active Provider: %s%s\n", cfg.Provider, from("provider"))
+
+		// Cache settings
+		if cfg.Cache.TranscriptTTLHours > 0 {
+			fmt.Printf("Transcript Cache: %d hours%s\n", cfg.Cache.TranscriptTTLHours, from("cache.transcript_ttl_hours"))
+		} else {
+			fmt.Printf("Transcript Cache: disabled%s\n", from("cache.transcript_ttl_hours"))
+		}
+		fmt.Printf("Response Cache: %v%s\n", cfg.Cache.Responses, from("cache.responses"))
+		fmt.Printf("Watched Channels: %d (every %d minutes)%s\n", len(cfg.Watch.Channels), cfg.Watch.IntervalMinutes, from("watch.channels"))
+		fmt.Printf("Summary Styles: %s\n", strings.Join(cfg.SummaryStyleNames(), ", "))
+		fmt.Printf("API Key Stores: %s (saving to %s)%s\n", strings.Join(cfg.Secrets.Order, ", "), cfg.Secrets.Store, from("secrets.order"))
+
+		// Prompts changed from the built-in defaults
+		var prompts []string
+		for _, name := range cfg.SummaryStyleNames() {
+			prompts = append(prompts, "summaries."+name+".system_prompt")
+		}
+		prompts = append(prompts, "transcripts.system_prompt", "queries.system_prompt", "chat.system_prompt")
+		customized := false
+		for _, key := range prompts {
+			if sources.Of(key) == "default" {
+				continue
+			}
+			if !customized {
+				fmt.Println("Customized Prompts:")
+				customized = true
+			}
+			fmt.Printf("  %s%s\n", key, from(key))
+		}
 
 		// Show available providers
 		fmt.Println("\nProvider Settings")
 		fmt.Println("│")
 		fmt.Println("├── LM Studio")
-		fmt.Printf("│   ├── Base URL: %s\n", cfg.Providers.LMStudio.BaseURL)
-		fmt.Printf("│   └── Model: %s\n", cfg.Providers.LMStudio.Model)
+		fmt.Printf("│   ├── Base URL: %s%s\n", cfg.Providers.LMStudio.BaseURL, from("providers.lmstudio.base_url"))
+		fmt.Printf("│   ├── Model: %s%s\n", cfg.Providers.LMStudio.Model, from("providers.lmstudio.model"))
+		if cfg.Providers.LMStudio.Temperature != nil {
+			fmt.Printf("│   ├── Temperature: %.1f%s\n", *cfg.Providers.LMStudio.Temperature, from("providers.lmstudio.temperature"))
+		}
+		fmt.Printf("│   └── Chunking: %s%s\n", formatChunking(cfg.Providers.LMStudio.Chunking), from("providers.lmstudio.chunking.chunk_tokens"))
 		fmt.Println("├── Ollama")
-		fmt.Printf("│   ├── Base URL: %s\n", cfg.Providers.Ollama.BaseURL)
-		fmt.Printf("│   └── Model: %s\n", cfg.Providers.Ollama.Model)
+		fmt.Printf("│   ├── Base URL: %s%s\n", cfg.Providers.Ollama.BaseURL, from("providers.ollama.base_url"))
+		fmt.Printf("│   ├── Model: %s%s\n", cfg.Providers.Ollama.Model, from("providers.ollama.model"))
+		if cfg.Providers.Ollama.Temperature != nil {
+			fmt.Printf("│   ├── Temperature: %.1f%s\n", *cfg.Providers.Ollama.Temperature, from("providers.ollama.temperature"))
+		}
+		fmt.Printf("│   └── Chunking: %s%s\n", formatChunking(cfg.Providers.Ollama.Chunking), from("providers.ollama.chunking.chunk_tokens"))
 		fmt.Println("├── Claude")
-		fmt.Printf("│   ├── Model: %s\n", cfg.Providers.Claude.Model)
-		fmt.Printf("│   ├── Temperature: %.1f\n", cfg.Providers.Claude.Temperature)
-		fmt.Printf("│   ├── Max Tokens: %d\n", cfg.Providers.Claude.MaxTokens)
-		fmt.Printf("│   ├── Timeout: %d seconds\n", cfg.Providers.Claude.TimeoutSecs)
-		fmt.Printf("│   ├── Max Retries: %d\n", cfg.Providers.Claude.MaxRetries)
-		fmt.Printf("│   └── API Key Set: %v\n", isClaudeAPIKeySet)
-		fmt.Println("└── OpenAI")
-		fmt.Printf("    ├── Model: %s\n", cfg.Providers.OpenAI.Model)
-		fmt.Printf("    ├── Temperature: %.1f\n", cfg.Providers.OpenAI.Temperature)
-		fmt.Printf("    ├── Max Tokens: %d\n", cfg.Providers.OpenAI.MaxTokens)
-		fmt.Printf("    ├── Timeout: %d seconds\n", cfg.Providers.OpenAI.TimeoutSecs)
-		fmt.Printf("    ├── Max Retries: %d\n", cfg.Providers.OpenAI.MaxRetries)
+		fmt.Printf("│   ├── Base URL: %s%s\n", cfg.Providers.Claude.BaseURL, from("providers.claude.base_url"))
+		if len(cfg.Providers.Claude.Headers) > 0 {
+			fmt.Printf("│   ├── Headers: %s%s\n", formatHeaders(cfg.Providers.Claude.Headers), from(headersKey(sources, "providers.claude.headers", cfg.Providers.Claude.Headers)))
+		}
+		fmt.Printf("│   ├── Model: %s%s\n", cfg.Providers.Claude.Model, from("providers.claude.model"))
+		fmt.Printf("│   ├── Temperature: %.1f%s\n", cfg.Providers.Claude.Temperature, from("providers.claude.temperature"))
+		fmt.Printf("│   ├── Max Tokens: %d%s\n", cfg.Providers.Claude.MaxTokens, from("providers.claude.max_tokens"))
+		fmt.Printf("│   ├── Timeout: %d seconds%s\n", cfg.Providers.Claude.TimeoutSecs, from("providers.claude.timeout_seconds"))
+		fmt.Printf("│   ├── Max Retries: %d%s\n", cfg.Providers.Claude.MaxRetries, from("providers.claude.max_retries"))
+		fmt.Printf("│   ├── Chunking: %s%s\n", formatChunking(cfg.Providers.Claude.Chunking), from("providers.claude.chunking.chunk_tokens"))
+		fmt.Printf("│   └── API Key: %s\n", apiKeyStatus(keyManager, "claude"))
+		fmt.Println("├── OpenAI")
+		fmt.Printf("│   ├── Base URL: %s%s\n", cfg.Providers.OpenAI.BaseURL, from("providers.openai.base_url"))
+		if len(cfg.Providers.OpenAI.Headers) > 0 {
+			fmt.Printf("│   ├── Headers: %s%s\n", formatHeaders(cfg.Providers.OpenAI.Headers), from(headersKey(sources, "providers.openai.headers", cfg.Providers.OpenAI.Headers)))
+		}
+		fmt.Printf("│   ├── Model: %s%s\n", cfg.Providers.OpenAI.Model, from("providers.openai.model"))
+		fmt.Printf("│   ├── Temperature: %.1f%s\n", cfg.Providers.OpenAI.Temperature, from("providers.openai.temperature"))
+		fmt.Printf("│   ├── Max Tokens: %d%s\n", cfg.Providers.OpenAI.MaxTokens, from("providers.openai.max_tokens"))
+		fmt.Printf("│   ├── Timeout: %d seconds%s\n", cfg.Providers.OpenAI.TimeoutSecs, from("providers.openai.timeout_seconds"))
+		fmt.Printf("│   ├── Max Retries: %d%s\n", cfg.Providers.OpenAI.MaxRetries, from("providers.openai.max_retries"))
+		fmt.Printf("│   ├── Chunking: %s%s\n", formatChunking(cfg.Providers.OpenAI.Chunking), from("providers.openai.chunking.chunk_tokens"))
 		if cfg.Providers.OpenAI.OrgID != "" {
-			fmt.Printf("    ├── Organization ID: %s\n", cfg.Providers.OpenAI.OrgID)
+			fmt.Printf("│   ├── Organization ID: %s%s\n", cfg.Providers.OpenAI.OrgID, from("providers.openai.organization_id"))
+		}
+		fmt.Printf("│   └── API Key: %s\n", apiKeyStatus(keyManager, "openai"))
+		fmt.Println("└── Gemini")
+		fmt.Printf("    ├── Base URL: %s%s\n", cfg.Providers.Gemini.BaseURL, from("providers.gemini.base_url"))
+		fmt.Printf("    ├── Model: %s%s\n", cfg.Providers.Gemini.Model, from("providers.gemini.model"))
+		fmt.Printf("    ├── Temperature: %.1f%s\n", cfg.Providers.Gemini.Temperature, from("providers.gemini.temperature"))
+		fmt.Printf("    ├── Max Output Tokens: %d%s\n", cfg.Providers.Gemini.MaxOutputTokens, from("providers.gemini.max_output_tokens"))
+		fmt.Printf("    ├── Timeout: %d seconds%s\n", cfg.Providers.Gemini.TimeoutSecs, from("providers.gemini.timeout_seconds"))
+		fmt.Printf("    ├── Max Retries: %d%s\n", cfg.Providers.Gemini.MaxRetries, from("providers.gemini.max_retries"))
+		fmt.Printf("    ├── Chunking: %s%s\n", formatChunking(cfg.Providers.Gemini.Chunking), from("providers.gemini.chunking.chunk_tokens"))
+		fmt.Printf("    └── API Key: %s\n", apiKeyStatus(keyManager, "gemini"))
+
+		// Show user-defined endpoints
+		endpoints := cfg.ProviderNames()[len(config.BuiltinProviders):]
+		if len(endpoints) > 0 {
+			fmt.Println("\nEndpoints")
+			fmt.Println("│")
+		}
+		for i, name := range endpoints {
+			endpoint, _ := cfg.Endpoint(name)
+			key := "endpoints." + name
+			settings := []string{
+				"Type: " + endpoint.Type,
+				"Base URL: " + endpoint.BaseURL + from(key+".base_url"),
+			}
+			if len(endpoint.Headers) > 0 {
+				settings = append(settings, "Headers: "+formatHeaders(endpoint.Headers)+from(headersKey(sources, key+".headers", endpoint.Headers)))
+			}
+			settings = append(settings, "Model: "+endpoint.Model+from(key+".model"))
+			if endpoint.Temperature != nil {
+				settings = append(settings, fmt.Sprintf("Temperature: %.1f%s", *endpoint.Temperature, from(key+".temperature")))
+			}
+			if endpoint.TopP != nil {
+				settings = append(settings, fmt.Sprintf("Top P: %.2f%s", *endpoint.TopP, from(key+".top_p")))
+			}
+			if endpoint.MaxTokens > 0 {
+				settings = append(settings, fmt.Sprintf("Max Tokens: %d%s", endpoint.MaxTokens, from(key+".max_tokens")))
+			}
+			settings = append(settings,
+				fmt.Sprintf("Timeout: %d seconds%s", endpoint.TimeoutSecs, from(key+".timeout_seconds")),
+				fmt.Sprintf("Max Retries: %d%s", endpoint.MaxRetries, from(key+".max_retries")),
+				"Chunking: "+formatChunking(endpoint.Chunking)+from(key+".chunking.chunk_tokens"),
+			)
+			if endpoint.APIKeyRef != "" {
+				settings = append(settings, fmt.Sprintf("API Key: %s, %s", endpoint.APIKeyRef, apiKeyStatus(keyManager, endpoint.APIKeyRef)))
+			} else {
+				settings = append(settings, "API Key: none")
+			}
+
+			branch, indent := "├── ", "│   "
+			if i == len(endpoints)-1 {
+				branch, indent = "└── ", "    "
+			}
+			fmt.Println(branch + name)
+			for j, setting := range settings {
+				if j == len(settings)-1 {
+					fmt.Println(indent + "└── " + setting)
+				} else {
+					fmt.Println(indent + "├── " + setting)
+				}
+			}
 		}
-		fmt.Printf("    └── API Key Set: %v\n", keyManager.HasAPIKey("openai"))
 
 		return nil
 	},
 }
+
+// apiKeyStatus reports which secret store has a provider's API key
+func apiKeyStatus(keyManager *config.APIKeyManager, provider string) string {
+	_, store, err := keyManager.LookupAPIKey(provider)
+	if err != nil {
+		return "not set"
+	}
+	return "set (" + store + ")"
+}
+
+// formatHeaders lists header names, leaving out the values since they
+// often hold credentials
+func formatHeaders(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// headersKey returns the config key whose source describes a provider's
+// headers: the whole map when it came from the environment, otherwise any
+// one header
+func headersKey(sources config.Sources, key string, headers map[string]string) string {
+	if sources.Of(key) != "default" {
+		return key
+	}
+	for name := range headers {
+		return key + "." + name
+	}
+	return key
+}
+
+// formatChunking describes chunking settings in one line
+func formatChunking(c config.ChunkingConfig) string {
+	if c.ChunkTokens <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%d tokens per chunk, %d overlap", c.ChunkTokens, c.OverlapTokens)
+}
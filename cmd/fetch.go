@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+var (
+	languages    []string
+	translateTo  string
+	noCache      bool
+	refreshCache bool
+)
+
+// addFetchFlags registers the flags that control how transcripts are fetched
+func addFetchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&languages, "lang", nil, "preferred caption languages in priority order (e.g. en,en-GB,de)")
+	cmd.Flags().StringVar(&translateTo, "translate-to", "", "translate the transcript into this language (e.g. en)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "don't read or write the transcript or response caches")
+	cmd.Flags().BoolVar(&refreshCache, "refresh", false, "re-fetch the transcript, ignoring any cached copy")
+}
+
+// requestOptions builds the per-request options from the fetch flags,
+// followed by extra
+func requestOptions(extra ...yts.RequestOption) []yts.RequestOption {
+	opts := []yts.RequestOption{
+		yts.Languages(languages...),
+		yts.TranslateTo(translateTo),
+		yts.OnStatus(func(msg string) {
+			fmt.Fprintln(os.Stderr, msg)
+		}),
+	}
+	if refreshCache {
+		opts = append(opts, yts.Refresh())
+	}
+	return append(opts, extra...)
+}
+
+// newClient creates a client for the config. Transcripts are cached on disk
+// unless disabled by --no-cache or the config, and LLM responses are cached
+// when cache.responses is enabled.
+func newClient(cfg *config.Config, opts ...yts.Option) (*yts.Client, error) {
+	clientOpts := []yts.Option{yts.WithConfig(cfg)}
+
+	if !noCache && (cfg.Cache.TranscriptTTLHours > 0 || cfg.Cache.Responses) {
+		cacheDir, err := yts.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Cache.TranscriptTTLHours > 0 {
+			ttl := time.Duration(cfg.Cache.TranscriptTTLHours) * time.Hour
+			clientOpts = append(clientOpts, yts.WithTranscriptCache(yts.NewFileTranscriptCache(cacheDir, ttl)))
+		}
+		if cfg.Cache.Responses {
+			clientOpts = append(clientOpts, yts.WithResponseCache(yts.NewFileResponseCache(cacheDir)))
+		}
+	}
+
+	return yts.New(append(clientOpts, opts...)...)
+}
+
+// transcriptText returns the transcript as plain text, one entry per line
+func transcriptText(result *yts.Transcript, withTimestamps bool) string {
+	if withTimestamps {
+		return result.TimestampedText()
+	}
+	return result.Text()
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/cache"
+	"github.com/conormkelly/yts-cli/internal/mcp"
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+// transcriptResourcePrefix is the URI scheme for cached transcript resources:
+// yts://transcripts/<videoID>/<cache key>
+const transcriptResourcePrefix = "yts://transcripts/"
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server over stdio",
+	Long: `Run an MCP server on stdin/stdout so MCP-capable assistants can fetch
+transcripts and summarize videos with your configured provider.
+
+Tools:
+  get_transcript   Fetch a video's transcript
+  summarize_video  Summarize a video in any configured style (see 'yts styles')
+  ask_video        Answer a question about a video
+
+Cached transcripts are exposed as resources. To use it, add a server to your
+assistant's MCP configuration with the command "yts" and the argument "mcp".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		client, err := newClient(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize client: %v", err)
+		}
+		if _, err := client.Provider(); err != nil {
+			return err
+		}
+
+		tools := &mcpTools{client: client}
+		server := mcp.NewServer("yts", version)
+		tools.register(server)
+
+		if cfg.Cache.TranscriptTTLHours > 0 {
+			store, err := transcriptStore(cmd)
+			if err != nil {
+				return err
+			}
+			server.SetResources(&transcriptResources{store: store})
+		}
+
+		// stdout carries the protocol, so diagnostics go to stderr
+		fmt.Fprintf(os.Stderr, "yts MCP server ready (provider: %s)\n", cfg.Provider)
+		return server.Serve(cmd.Context(), os.Stdin, os.Stdout)
+	},
+}
+
+// mcpTools implements the MCP tools on top of the client
+type mcpTools struct {
+	client *yts.Client
+}
+
+func (t *mcpTools) register(s *mcp.Server) {
+	s.AddTool(mcp.Tool{
+		Name:        "get_transcript",
+		Description: "Fetch the transcript of a YouTube video.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"url": {"type": "string", "description": "YouTube video URL"},
+				"lang": {"type": "array", "items": {"type": "string"}, "description": "Preferred caption languages in priority order, e.g. [\"en\", \"de\"]"},
+				"timestamps": {"type": "boolean", "description": "Prefix each line with its start time in seconds"}
+			},
+			"required": ["url"]
+		}`),
+	}, t.getTranscript)
+
+	s.AddTool(mcp.Tool{
+		Name:        "summarize_video",
+		Description: "Summarize a YouTube video from its transcript.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"url": {"type": "string", "description": "YouTube video URL"},
+				"style": {"type": "string", "description": "Summary style: short (default), long, or a style from the user's config"},
+				"lang": {"type": "array", "items": {"type": "string"}, "description": "Preferred caption languages in priority order"}
+			},
+			"required": ["url"]
+		}`),
+	}, t.summarizeVideo)
+
+	s.AddTool(mcp.Tool{
+		Name:        "ask_video",
+		Description: "Answer a question about a YouTube video using only its transcript.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"url": {"type": "string", "description": "YouTube video URL"},
+				"question": {"type": "string", "description": "The question to answer"},
+				"lang": {"type": "array", "items": {"type": "string"}, "description": "Preferred caption languages in priority order"}
+			},
+			"required": ["url", "question"]
+		}`),
+	}, t.askVideo)
+}
+
+func (t *mcpTools) getTranscript(ctx context.Context, raw json.RawMessage) (string, error) {
+	var args struct {
+		URL        string   `json:"url"`
+		Languages  []string `json:"lang"`
+		Timestamps bool     `json:"timestamps"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+
+	result, err := t.fetch(ctx, args.URL, args.Languages)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Title: %s\nLanguage: %s\n\n%s", result.Title, result.Language, transcriptText(result, args.Timestamps)), nil
+}
+
+func (t *mcpTools) summarizeVideo(ctx context.Context, raw json.RawMessage) (string, error) {
+	var args struct {
+		URL       string   `json:"url"`
+		Style     string   `json:"style"`
+		Languages []string `json:"lang"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+
+	if err := t.client.ValidateStyle(args.Style); err != nil {
+		return "", err
+	}
+
+	result, err := t.fetch(ctx, args.URL, args.Languages)
+	if err != nil {
+		return "", err
+	}
+
+	summary, err := t.client.SummarizeTranscript(ctx, result, yts.Style(args.Style))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate summary: %v", err)
+	}
+	return fmt.Sprintf("Title: %s\n\n%s", result.Title, summary), nil
+}
+
+func (t *mcpTools) askVideo(ctx context.Context, raw json.RawMessage) (string, error) {
+	var args struct {
+		URL       string   `json:"url"`
+		Question  string   `json:"question"`
+		Languages []string `json:"lang"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %v", err)
+	}
+	if strings.TrimSpace(args.Question) == "" {
+		return "", fmt.Errorf("question is required")
+	}
+
+	result, err := t.fetch(ctx, args.URL, args.Languages)
+	if err != nil {
+		return "", err
+	}
+
+	answer, err := t.client.AskTranscript(ctx, result, args.Question)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate answer: %v", err)
+	}
+	return answer, nil
+}
+
+func (t *mcpTools) fetch(ctx context.Context, url string, languages []string) (*yts.Transcript, error) {
+	if url == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	result, err := t.client.Transcript(ctx, url, yts.Languages(languages...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+	return result, nil
+}
+
+// transcriptResourceURI returns the resource URI of a cached transcript. Cache
+// keys such as "de,en>fr" are escaped so each part stays one path segment.
+func transcriptResourceURI(videoID, key string) string {
+	return transcriptResourcePrefix + url.PathEscape(videoID) + "/" + url.PathEscape(key)
+}
+
+// transcriptResources exposes the transcript cache as MCP resources
+type transcriptResources struct {
+	store *cache.TranscriptStore
+}
+
+func (r *transcriptResources) List() ([]mcp.Resource, error) {
+	entries, err := r.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]mcp.Resource, 0, len(entries))
+	for _, entry := range entries {
+		if r.store.Expired(entry) {
+			continue
+		}
+		resources = append(resources, mcp.Resource{
+			URI:  transcriptResourceURI(entry.VideoID, entry.Key),
+			Name: entry.Transcript.Title,
+			Description: fmt.Sprintf("Transcript of https://www.youtube.com/watch?v=%s (%s), fetched %s",
+				entry.VideoID, entry.Transcript.Language, entry.FetchedAt.Format(time.DateTime)),
+			MimeType: "text/plain",
+		})
+	}
+	return resources, nil
+}
+
+func (r *transcriptResources) Read(uri string) (string, string, error) {
+	videoID, key, ok := strings.Cut(strings.TrimPrefix(uri, transcriptResourcePrefix), "/")
+	if !ok || !strings.HasPrefix(uri, transcriptResourcePrefix) {
+		return "", "", fmt.Errorf("resource not found: %s", uri)
+	}
+	videoID, err := url.PathUnescape(videoID)
+	if err != nil {
+		return "", "", fmt.Errorf("resource not found: %s", uri)
+	}
+	key, err = url.PathUnescape(key)
+	if err != nil {
+		return "", "", fmt.Errorf("resource not found: %s", uri)
+	}
+
+	result, ok := r.store.Get(videoID, key)
+	if !ok {
+		return "", "", fmt.Errorf("resource not found: %s", uri)
+	}
+	return "text/plain", fmt.Sprintf("Title: %s\n\n%s", result.Title, result.Text()), nil
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
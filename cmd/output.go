@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeOutputFile writes content to path, expanding a leading ~/ and creating
+// parent directories as needed. It returns the expanded path.
+func writeOutputFile(path string, content string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	return path, nil
+}
+
+// expandHome replaces a leading ~/ in path with the user's home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(homeDir, path[2:]), nil
+}
+
+// formatOutput builds the output file content for a summary or query response
+func formatOutput(title string, translationNote string, question string, response string) string {
+	header := fmt.Sprintf("Title: %s\n", title)
+	if translationNote != "" {
+		header += fmt.Sprintf("Translation: %s\n", translationNote)
+	}
+
+	if question != "" {
+		return fmt.Sprintf("%s\nQuestion: %s\n\n%s", header, question, response)
+	}
+	return fmt.Sprintf("%s\n%s", header, response)
+}
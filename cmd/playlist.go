@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+const (
+	playlistOverviewFile = "overview.md"
+	playlistIndexFile    = "index.md"
+)
+
+var (
+	playlistOutputDir string
+	playlistLimit     int
+)
+
+var playlistCmd = &cobra.Command{
+	Use:   "playlist [playlist-url]",
+	Short: "Summarize every video in a playlist",
+	Long: `Summarize each video in a YouTube playlist, then combine the summaries into
+a course overview of the whole playlist.
+
+Everything is written to --output-dir (by default, a directory named after the
+playlist): one file per video, overview.md, and index.md linking them together.
+Videos are processed the same way as 'yts batch', so --workers,
+--llm-concurrency, --filename and --resume work the same way.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		client, err := newBatchClient(cfg)
+		if err != nil {
+			return err
+		}
+
+		playlist, err := client.Playlist(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if playlistLimit > 0 && len(playlist.Videos) > playlistLimit {
+			playlist.Videos = playlist.Videos[:playlistLimit]
+		}
+		fmt.Fprintf(os.Stderr, "Playlist: %s (%d videos)\n", playlist.Title, len(playlist.Videos))
+
+		outputDir := playlistOutputDir
+		if outputDir == "" {
+			outputDir = sanitizeFilename(playlist.Title)
+		}
+
+		b, err := newBatchRun(client, outputDir, batchFilenameFormat)
+		if err != nil {
+			return err
+		}
+
+		urls := make([]string, len(playlist.Videos))
+		for i, v := range playlist.Videos {
+			urls[i] = v.URL()
+		}
+		results := b.run(ctx, urls)
+		printBatchResults(os.Stdout, results)
+
+		if ctx.Err() != nil {
+			return errInterrupted
+		}
+
+		overview, err := b.playlistOverview(ctx, playlist, results)
+		if err != nil {
+			return err
+		}
+
+		indexPath := filepath.Join(b.outputDir, playlistIndexFile)
+		if _, err := writeOutputFile(indexPath, playlistIndex(playlist, overview, results)); err != nil {
+			return err
+		}
+		fmt.Printf("\nIndex saved to %s\n", indexPath)
+
+		return batchError(ctx, results)
+	},
+}
+
+// playlistOverview combines the per-video summaries into a course overview,
+// streaming it to stdout and saving it in the output directory. Summaries
+// that don't fit the provider's chunk size are condensed in groups first.
+// It returns the saved path, or "" when no video was summarized.
+func (b *batchRun) playlistOverview(ctx context.Context, playlist *yts.Playlist, results []*batchResult) (string, error) {
+	var summaries []string
+	for _, r := range results {
+		if r.Output == "" {
+			continue
+		}
+		content, err := os.ReadFile(r.Output)
+		if err != nil {
+			return "", fmt.Errorf("failed to read summary: %v", err)
+		}
+		summaries = append(summaries, fmt.Sprintf("Video %d: %s\n\n%s", r.Index, r.Title, strings.TrimSpace(string(content))))
+	}
+	if len(summaries) == 0 {
+		return "", nil
+	}
+
+	fmt.Printf("\nCourse overview: %s\n\n", playlist.Title)
+
+	overview, err := b.client.PlaylistOverview(ctx, playlist, summaries, yts.OnText(func(text string) {
+		fmt.Print(text)
+	}))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate overview: %v", err)
+	}
+
+	content := fmt.Sprintf("Playlist: %s\n\n%s\n", playlist.Title, overview)
+	return writeOutputFile(filepath.Join(b.outputDir, playlistOverviewFile), content)
+}
+
+// playlistIndex renders index.md, linking the overview and each video's summary
+func playlistIndex(playlist *yts.Playlist, overview string, results []*batchResult) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("# %s\n\n", playlist.Title))
+	out.WriteString(fmt.Sprintf("Source: https://www.youtube.com/playlist?list=%s\n\n", playlist.ID))
+	if overview != "" {
+		out.WriteString(fmt.Sprintf("Overview: [%s](%s)\n\n", playlistOverviewFile, playlistOverviewFile))
+	}
+
+	out.WriteString("## Videos\n\n")
+	for i, r := range results {
+		title := r.Title
+		if title == "" {
+			title = playlist.Videos[i].Title
+		}
+		if r.Output == "" {
+			out.WriteString(fmt.Sprintf("%d. %s ([watch](%s)) - failed: %v\n", r.Index, title, r.URL, r.Err))
+			continue
+		}
+		out.WriteString(fmt.Sprintf("%d. [%s](<%s>) ([watch](%s))\n", r.Index, title, filepath.Base(r.Output), r.URL))
+	}
+
+	return out.String()
+}
+
+func init() {
+	rootCmd.AddCommand(playlistCmd)
+
+	playlistCmd.Flags().StringVarP(&playlistOutputDir, "output-dir", "d", "", "directory to write summaries to (default: the playlist title)")
+	playlistCmd.Flags().StringVar(&batchFilenameFormat, "filename", "{{.Index}} - {{.Title}}.md", "output filename template (fields: .Index, .VideoID, .Title)")
+	playlistCmd.Flags().IntVarP(&batchWorkers, "workers", "w", 4, "number of videos to process at once")
+	playlistCmd.Flags().IntVar(&batchLLMConcurrency, "llm-concurrency", 0, "maximum concurrent LLM requests (default 1 for local providers, 4 otherwise)")
+	playlistCmd.Flags().BoolVar(&batchResume, "resume", false, "skip videos already completed in the output directory")
+	playlistCmd.Flags().IntVar(&playlistLimit, "limit", 0, "only summarize the first n videos")
+	addStyleFlags(playlistCmd)
+	addFetchFlags(playlistCmd)
+}
@@ -2,25 +2,28 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/conormkelly/yts-cli/internal/config"
-	"github.com/conormkelly/yts-cli/internal/llm"
-	"github.com/conormkelly/yts-cli/internal/transcript"
+	"github.com/conormkelly/yts-cli/pkg/yts"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
 var (
-	longSummary bool
-	provider    string // ollama, LM Studio etc
-	outputFile  string
-	query       string
+	longSummary  bool
+	summaryStyle string
+	provider     string // ollama, LM Studio etc
+	profileName  string
+	outputFile   string
+	query        string
 )
 
 var rootCmd = &cobra.Command{
@@ -29,144 +32,157 @@ var rootCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		videoURL := args[0]
+		ctx := cmd.Context()
 
 		// Get configuration
-		cfg, err := config.GetConfig()
+		cfg, err := loadConfig(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get config: %v", err)
 		}
 
-		// Initialize transcript fetcher
-		fetcher := transcript.NewTranscriptFetcher()
+		client, err := newClient(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to initialize transcript fetcher: %v", err)
+			return fmt.Errorf("failed to initialize client: %v", err)
 		}
 
 		// Initialize LLM client using config
-		llmClient, err := llm.NewProvider(cfg)
-		if err != nil {
-			return fmt.Errorf("failed to initialize llm client: %v", err)
+		if _, err := client.Provider(); err != nil {
+			return err
+		}
+		if query == "" {
+			if err := client.ValidateStyle(getSummaryType()); err != nil {
+				return err
+			}
 		}
 
-		// Fetch transcript
-		title, transcript, err := fetcher.Fetch(videoURL)
+		// Fetch transcript, translating with the LLM if YouTube couldn't
+		result, err := client.Transcript(ctx, videoURL, requestOptions()...)
 		if err != nil {
+			var playlistErr *yts.ErrPlaylistURL
+			if errors.As(err, &playlistErr) {
+				return fmt.Errorf("%v; use 'yts playlist' to summarize every video in it", err)
+			}
 			return fmt.Errorf("failed to fetch transcript: %v", err)
 		}
+		title := result.Title
+		translationNote := result.TranslationNote()
 
-		fmt.Printf("\nTitle: %s\n\n", title)
+		fmt.Printf("\nTitle: %s\n", title)
+		if translationNote != "" {
+			fmt.Printf("Translation: %s\n", translationNote)
+		}
+		fmt.Println()
 
-		// Determine mode and get appropriate system prompt
-		var mode string
-		var systemPrompt string
+		// Generate response using streaming
+		var response strings.Builder
+		onText := yts.OnText(func(chunk string) {
+			fmt.Print(chunk)
+			response.WriteString(chunk)
+		})
 
+		mode := "summary"
 		if query != "" {
-			// Query mode
 			mode = "query"
-			systemPrompt = cfg.Queries.SystemPrompt
-			// Replace placeholders with actual values
-			systemPrompt = strings.ReplaceAll(systemPrompt, "{{title}}", title)
-			systemPrompt = strings.ReplaceAll(systemPrompt, "{{query}}", query)
-
 			// Display query
 			fmt.Printf("Question: %s\n\n", query)
+			_, err = client.AskTranscript(ctx, result, query, onText)
 		} else {
-			// Summary mode
-			mode = "summary"
-			resolvedSummaryType := getSummaryType()
-			systemPrompt = config.GetSystemPrompt(resolvedSummaryType)
-			if systemPrompt == "" {
-				return fmt.Errorf("failed to get system prompt for summary type: %s", resolvedSummaryType)
-			}
-		}
-
-		// Process transcript text
-		var transcriptText strings.Builder
-		for i := range transcript {
-			transcriptText.WriteString(transcript[i].Text + "\n")
+			_, err = client.SummarizeTranscript(ctx, result, yts.Style(getSummaryType()), onText,
+				yts.OnProgress(func(current, total int) {
+					// Transcripts too long for the provider's context are summarized in sections
+					fmt.Fprintf(os.Stderr, "Summarizing section %d of %d...\n", current, total)
+				}))
 		}
 
-		// Generate response using streaming
-		var response strings.Builder
-		err = llmClient.Stream(systemPrompt, transcriptText.String(), func(chunk string) {
-			fmt.Print(chunk)
-			response.WriteString(chunk)
-		})
-		if err != nil {
+		// On Ctrl-C, keep whatever was generated so far
+		interrupted := ctx.Err() != nil
+		if err != nil && !interrupted {
 			return fmt.Errorf("failed to generate %s: %v", mode, err)
 		}
+		if interrupted {
+			response.WriteString(interruptedMarker)
+			fmt.Print(interruptedMarker)
+		}
 		// Add newline
 		response.WriteString("\n")
 		fmt.Println()
 
 		// Handle output file if specified
 		if outputFile != "" {
-			// Handle home directory expansion
-			if len(outputFile) >= 2 && outputFile[:2] == "~/" {
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					return fmt.Errorf("failed to get home directory: %v", err)
-				}
-				outputFile = filepath.Join(homeDir, outputFile[2:])
-			}
+			outputContent := formatOutput(title, translationNote, query, response.String())
 
-			// Ensure directory exists
-			outputDir := filepath.Dir(outputFile)
-			if err := os.MkdirAll(outputDir, 0755); err != nil {
-				return fmt.Errorf("failed to create output directory: %v", err)
-			}
-
-			// Create output content based on mode
-			var outputContent string
-			if mode == "query" {
-				outputContent = fmt.Sprintf("Title: %s\n\nQuestion: %s\n\n%s",
-					title, query, response.String())
-			} else {
-				outputContent = fmt.Sprintf("Title: %s\n\n%s",
-					title, response.String())
-			}
-
-			if err := os.WriteFile(outputFile, []byte(outputContent), 0644); err != nil {
-				return fmt.Errorf("failed to write output file: %v", err)
+			savedPath, err := writeOutputFile(outputFile, outputContent)
+			if err != nil {
+				return err
 			}
 
 			// Use cases.Title instead of strings.Title
 			caser := cases.Title(language.English)
-			fmt.Printf("\n%s saved to %s\n", caser.String(mode), outputFile)
+			fmt.Printf("\n%s saved to %s\n", caser.String(mode), savedPath)
 		}
 
+		if interrupted {
+			return errInterrupted
+		}
 		return nil
 	},
 }
 
+// interruptedMarker is appended to partial output when generation is cancelled
+const interruptedMarker = "\n\n[interrupted]"
+
+var errInterrupted = errors.New("interrupted")
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel in-flight requests on Ctrl-C. Once the first signal has been
+	// handled, restore the default behaviour so a second Ctrl-C exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
-	rootCmd.Flags().StringVarP(&provider, "provider", "p", "", "LLM provider (lmstudio, ollama)")
-	rootCmd.Flags().BoolVarP(&longSummary, "long", "l", false, "Generate a detailed summary")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "configuration profile to use (see 'yts config profile list')")
+	rootCmd.Flags().StringVarP(&provider, "provider", "p", "", "LLM provider (lmstudio, ollama, claude, openai, gemini or an endpoint)")
+	addStyleFlags(rootCmd)
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file path")
 	rootCmd.Flags().StringVarP(&query, "query", "q", "", "Ask a specific question about the video content")
+	addFetchFlags(rootCmd)
+}
 
-	// Bind provider flag to viper
-	viper.BindPFlag("provider", rootCmd.Flags().Lookup("provider"))
+// configLoader returns a loader for the config file, the environment,
+// --profile and the command's --provider flag, if it has one
+func configLoader(cmd *cobra.Command) *config.Loader {
+	return config.NewLoader(
+		config.WithFlag("provider", cmd.Flags().Lookup("provider")),
+		config.WithFlag("profile", cmd.Flags().Lookup("profile")),
+	)
 }
 
-func initConfig() {
-	if err := config.Initialize(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error initializing config:", err)
-		os.Exit(1)
-	}
+// loadConfig loads the configuration for a command
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	return configLoader(cmd).Load()
+}
+
+// addStyleFlags registers the flags that select a summary style
+func addStyleFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&longSummary, "long", "l", false, "Generate a detailed summary (same as --style long)")
+	cmd.Flags().StringVar(&summaryStyle, "style", "", "summary style to use (see 'yts styles')")
 }
 
-// getSummaryType returns the appropriate summary type based on flags
+// getSummaryType returns the summary style selected by --style or --long
 func getSummaryType() string {
+	if summaryStyle != "" {
+		return summaryStyle
+	}
 	if longSummary {
 		return "long"
 	}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr        string
+	serveConcurrency int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP API server",
+	Long: `Serve transcripts, summaries and answers over HTTP.
+
+Endpoints:
+  POST /summaries              {"url": "...", "style": "short"}
+  POST /queries                {"url": "...", "query": "..."}
+  GET  /transcripts/{videoID}  ?lang=en,de&translate_to=en
+
+Request bodies also accept "lang" (a list of language codes) and
+"translate_to". Summaries and answers stream back as Server-Sent Events:
+"meta", then "text" events as text is generated, then "done" or "error".
+Errors before streaming starts are returned as JSON with a matching status.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		client, err := newClient(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize client: %v", err)
+		}
+		if _, err := client.Provider(); err != nil {
+			return err
+		}
+
+		srv := &http.Server{
+			Addr:    serveAddr,
+			Handler: server.New(client, serveConcurrency).Handler(),
+		}
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- srv.ListenAndServe()
+		}()
+		fmt.Fprintf(os.Stderr, "Listening on %s (provider: %s)\n", serveAddr, cfg.Provider)
+
+		select {
+		case err := <-serveErr:
+			return fmt.Errorf("server failed: %v", err)
+		case <-ctx.Done():
+		}
+
+		// Give in-flight requests a moment to finish before closing them
+		fmt.Fprintln(os.Stderr, "Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			srv.Close()
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "address to listen on")
+	serveCmd.Flags().IntVar(&serveConcurrency, "concurrency", 4, "maximum requests handled at once; extra requests get 503")
+}
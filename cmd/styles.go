@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// maxStylePromptLen truncates prompts in the style list
+const maxStylePromptLen = 60
+
+var stylesCmd = &cobra.Command{
+	Use:   "styles",
+	Short: "List the configured summary styles",
+	Long: `List the summary styles that can be used with --style. Add your own with
+'yts config set summaries.<name>.system_prompt "..."', and optionally override
+the provider, model or temperature for a style:
+
+  yts config set summaries.tldr.system_prompt "Summarize in three bullet points."
+  yts config set summaries.tldr.provider claude
+  yts config set summaries.tldr.temperature 0.2`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STYLE\tOVERRIDES\tPROMPT")
+		for _, name := range cfg.SummaryStyleNames() {
+			style := cfg.Summaries[name]
+
+			var overrides []string
+			if style.Provider != "" {
+				overrides = append(overrides, "provider="+style.Provider)
+			}
+			if style.Model != "" {
+				overrides = append(overrides, "model="+style.Model)
+			}
+			if style.Temperature != nil {
+				overrides = append(overrides, fmt.Sprintf("temperature=%g", *style.Temperature))
+			}
+			if len(overrides) == 0 {
+				overrides = append(overrides, "-")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, strings.Join(overrides, " "), promptPreview(style.SystemPrompt))
+		}
+		return w.Flush()
+	},
+}
+
+// promptPreview returns the first line of a prompt, truncated
+func promptPreview(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	runes := []rune(line)
+	if len(runes) > maxStylePromptLen {
+		return string(runes[:maxStylePromptLen]) + "…"
+	}
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(stylesCmd)
+}
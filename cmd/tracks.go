@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+	"github.com/spf13/cobra"
+)
+
+var tracksCmd = &cobra.Command{
+	Use:   "tracks [youtube-url]",
+	Short: "List the caption tracks available for a video",
+	Long: `List every caption track available for a video, including its language
+code, display name and whether it was auto-generated. Use the language codes
+with --lang to pick a specific track.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := yts.New()
+		if err != nil {
+			return fmt.Errorf("failed to initialize client: %v", err)
+		}
+
+		title, tracks, err := client.Tracks(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to list caption tracks: %v", err)
+		}
+
+		fmt.Printf("\nTitle: %s\n\n", title)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LANGUAGE\tNAME\tKIND")
+		for _, track := range tracks {
+			kind := "manual"
+			if track.IsGenerated() {
+				kind = "auto-generated"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", track.LanguageCode, track.Name, kind)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tracksCmd)
+}
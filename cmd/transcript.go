@@ -2,13 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
-	"github.com/conormkelly/yts-cli/internal/config"
-	"github.com/conormkelly/yts-cli/internal/llm"
-	"github.com/conormkelly/yts-cli/internal/transcript"
+	"github.com/conormkelly/yts-cli/pkg/yts"
 	"github.com/spf13/cobra"
 )
 
@@ -25,88 +21,82 @@ capitalization and punctuation. Use --raw for unformatted output.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		videoURL := args[0]
+		ctx := cmd.Context()
 
-		// Initialize transcript fetcher
-		fetcher := transcript.NewTranscriptFetcher()
+		// Get configuration
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
 
-		// Fetch transcript
-		title, rawTranscript, err := fetcher.Fetch(videoURL)
+		client, err := newClient(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to fetch transcript: %v", err)
+			return fmt.Errorf("failed to initialize client: %v", err)
 		}
 
-		fmt.Printf("\nTitle: %s\n\n", title)
+		// Fetch transcript. The LLM is only needed for formatting, and for
+		// translating when YouTube couldn't.
+		result, err := client.Transcript(ctx, videoURL, requestOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transcript: %v", err)
+		}
+		translationNote := result.TranslationNote()
 
-		var transcriptText strings.Builder
-		for i := range rawTranscript {
-			if includeTimestamps {
-				transcriptText.WriteString(fmt.Sprintf("[%.1fs]: %s\n", rawTranscript[i].Start, rawTranscript[i].Text))
-			} else {
-				transcriptText.WriteString(rawTranscript[i].Text + "\n")
-			}
+		fmt.Printf("\nTitle: %s\n", result.Title)
+		if translationNote != "" {
+			fmt.Printf("Translation: %s\n", translationNote)
 		}
+		fmt.Println()
 
 		var finalOutput string
+		var interrupted bool
 		if rawOutput {
 			// For raw output, just use the transcript text directly
-			finalOutput = transcriptText.String()
+			finalOutput = transcriptText(result, includeTimestamps)
 			fmt.Print(finalOutput)
 		} else {
-			// Get configuration for formatted output
-			cfg, err := config.GetConfig()
-			if err != nil {
-				return fmt.Errorf("failed to get config: %v", err)
-			}
-
-			// Initialize LLM client
-			llmClient, err := llm.NewProvider(cfg)
-			if err != nil {
-				return fmt.Errorf("failed to initialize provider: %v", err)
-			}
-
 			// Format transcript using streaming
 			var formattedTranscript strings.Builder
 
-			err = llmClient.Stream(
-				cfg.Transcripts.SystemPrompt,
-				transcriptText.String(),
-				func(chunk string) {
-					fmt.Print(chunk)
-					formattedTranscript.WriteString(chunk)
-				},
-			)
-			if err != nil {
+			opts := []yts.RequestOption{yts.OnText(func(chunk string) {
+				fmt.Print(chunk)
+				formattedTranscript.WriteString(chunk)
+			})}
+			if includeTimestamps {
+				opts = append(opts, yts.Timestamps())
+			}
+			_, err = client.FormatTranscript(ctx, result, opts...)
+			interrupted = ctx.Err() != nil
+			if err != nil && !interrupted {
 				return fmt.Errorf("failed to format transcript: %v", err)
 			}
+			if interrupted {
+				formattedTranscript.WriteString(interruptedMarker)
+				fmt.Print(interruptedMarker)
+			}
 			// Add a newline at the end of the stream
 			formattedTranscript.WriteString("\n")
 			fmt.Println()
 			finalOutput = formattedTranscript.String()
 		}
 
+		// Record how the transcript was translated
+		if translationNote != "" {
+			finalOutput = fmt.Sprintf("Translation: %s\n\n%s", translationNote, finalOutput)
+		}
+
 		// Handle output file if specified
 		if outputFile != "" {
-			// Handle home directory expansion
-			if outputFile[:2] == "~/" {
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					return fmt.Errorf("failed to get home directory: %v", err)
-				}
-				outputFile = filepath.Join(homeDir, outputFile[2:])
-			}
-
-			// Ensure directory exists
-			outputDir := filepath.Dir(outputFile)
-			if err := os.MkdirAll(outputDir, 0755); err != nil {
-				return fmt.Errorf("failed to create output directory: %v", err)
-			}
-
-			if err := os.WriteFile(outputFile, []byte(finalOutput), 0644); err != nil {
-				return fmt.Errorf("failed to write output file: %v", err)
+			savedPath, err := writeOutputFile(outputFile, finalOutput)
+			if err != nil {
+				return err
 			}
-			fmt.Printf("\nTranscript saved to %s\n", outputFile)
+			fmt.Printf("\nTranscript saved to %s\n", savedPath)
 		}
 
+		if interrupted {
+			return errInterrupted
+		}
 		return nil
 	},
 }
@@ -116,4 +106,5 @@ func init() {
 	transcriptCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file path")
 	transcriptCmd.Flags().BoolVarP(&rawOutput, "raw", "r", false, "output raw transcript without formatting")
 	transcriptCmd.Flags().BoolVarP(&includeTimestamps, "timestamps", "t", false, "include timestamps in the output")
+	addFetchFlags(transcriptCmd)
 }
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/feed"
+	"github.com/spf13/cobra"
+)
+
+// watchStateFile records processed and pending videos in the output directory
+const watchStateFile = ".yts-watch.json"
+
+var (
+	watchInterval       time.Duration
+	watchOnce           bool
+	watchOutputDir      string
+	watchFilenameFormat string
+	watchBackfill       bool
+	watchGiveUpAfter    time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [channel-id...]",
+	Short: "Summarize new uploads from YouTube channels",
+	Long: `Poll the uploads feed of each channel and summarize new videos into an
+output directory. Channels are given as channel IDs (UC...) or
+youtube.com/channel/ URLs, or taken from the watch.channels config setting.
+
+Processed videos are remembered in .yts-watch.json in the output directory.
+Videos whose captions aren't available yet are retried on every poll until
+--give-up-after has passed since they were first seen.
+
+The first time a channel is polled, its existing uploads are recorded without
+being summarized. Use --backfill to summarize them too.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %v", err)
+		}
+
+		channelArgs := args
+		if len(channelArgs) == 0 {
+			channelArgs = cfg.Watch.Channels
+		}
+		if len(channelArgs) == 0 {
+			return fmt.Errorf("no channels to watch\nPass channel IDs as arguments or set them with: yts config set watch.channels UC...,UC...")
+		}
+		channels := make([]string, 0, len(channelArgs))
+		for _, arg := range channelArgs {
+			id, err := feed.ChannelID(arg)
+			if err != nil {
+				return err
+			}
+			channels = append(channels, id)
+		}
+
+		interval := time.Duration(cfg.Watch.IntervalMinutes) * time.Minute
+		if cmd.Flags().Changed("interval") {
+			interval = watchInterval
+		}
+		if interval <= 0 && !watchOnce {
+			return fmt.Errorf("poll interval must be positive")
+		}
+
+		outputDir := cfg.Watch.OutputDir
+		if watchOutputDir != "" {
+			outputDir = watchOutputDir
+		}
+
+		client, err := newBatchClient(cfg)
+		if err != nil {
+			return err
+		}
+
+		b, err := newBatchRun(client, outputDir, watchFilenameFormat)
+		if err != nil {
+			return err
+		}
+		// Progress is tracked in the watch state instead of the batch manifest
+		b.manifest = nil
+
+		state, err := loadWatchState(b.outputDir)
+		if err != nil {
+			return err
+		}
+
+		w := &watcher{
+			batch:    b,
+			feeds:    feed.NewClient(cfg.Watch.FeedBaseURL),
+			channels: channels,
+			state:    state,
+		}
+
+		fmt.Fprintf(os.Stderr, "Watching %d channels, writing summaries to %s\n", len(channels), b.outputDir)
+		for {
+			w.poll(ctx)
+			if err := w.state.save(b.outputDir); err != nil {
+				return err
+			}
+
+			if watchOnce || ctx.Err() != nil {
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "Next check at %s\n", time.Now().Add(interval).Format("15:04"))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+// watcher polls channel feeds and summarizes new uploads
+type watcher struct {
+	batch    *batchRun
+	feeds    *feed.Client
+	channels []string
+	state    *watchState
+}
+
+// poll checks every feed for new uploads, then tries to summarize every
+// pending video
+func (w *watcher) poll(ctx context.Context) {
+	now := time.Now()
+
+	for _, channelID := range w.channels {
+		f, err := w.feeds.Fetch(ctx, channelID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", channelID, err)
+			continue
+		}
+
+		_, known := w.state.Channels[channelID]
+		for _, entry := range f.Entries {
+			if w.state.Processed[entry.VideoID] != nil || w.state.Pending[entry.VideoID] != nil {
+				continue
+			}
+			video := &watchVideo{
+				ChannelID: channelID,
+				Title:     entry.Title,
+				URL:       entry.URL,
+				Published: entry.Published,
+				FirstSeen: now,
+			}
+			if !known && !watchBackfill {
+				video.Skipped = "published before the channel was watched"
+				w.state.Processed[entry.VideoID] = video
+				continue
+			}
+			w.state.Pending[entry.VideoID] = video
+		}
+		if !known {
+			w.state.Channels[channelID] = now
+		}
+	}
+
+	if len(w.state.Pending) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	// Oldest first, so summaries are written in upload order
+	ids := make([]string, 0, len(w.state.Pending))
+	for id := range w.state.Pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return w.state.Pending[ids[i]].Published.Before(w.state.Pending[ids[j]].Published)
+	})
+
+	urls := make([]string, len(ids))
+	for i, id := range ids {
+		urls[i] = w.state.Pending[id].URL
+	}
+	results := w.batch.run(ctx, urls)
+
+	for i, r := range results {
+		if ctx.Err() != nil && r.Status != batchDone {
+			continue
+		}
+
+		video := w.state.Pending[ids[i]]
+		video.Attempts++
+		if r.Status == batchDone {
+			video.Title = r.Title
+			video.Output = r.Output
+			video.LastError = ""
+			processedAt := time.Now()
+			video.ProcessedAt = &processedAt
+			w.state.Processed[ids[i]] = video
+			delete(w.state.Pending, ids[i])
+			continue
+		}
+
+		video.LastError = r.Err.Error()
+		if time.Since(video.FirstSeen) > watchGiveUpAfter {
+			fmt.Fprintf(os.Stderr, "Giving up on %s after %d attempts\n", video.Title, video.Attempts)
+			video.Skipped = "gave up after " + watchGiveUpAfter.String()
+			w.state.Processed[ids[i]] = video
+			delete(w.state.Pending, ids[i])
+		}
+	}
+}
+
+// watchVideo is a video found in a feed
+type watchVideo struct {
+	ChannelID   string     `json:"channel_id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Published   time.Time  `json:"published"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	Attempts    int        `json:"attempts,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Output      string     `json:"output,omitempty"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	Skipped     string     `json:"skipped,omitempty"` // Why the video wasn't summarized
+}
+
+// watchState is persisted between polls and between runs. Videos that
+// were summarized or given up on are in Processed; videos still waiting
+// for captions or a successful retry are in Pending.
+type watchState struct {
+	Channels  map[string]time.Time   `json:"channels"` // When each channel was first polled
+	Processed map[string]*watchVideo `json:"processed"`
+	Pending   map[string]*watchVideo `json:"pending"`
+}
+
+func loadWatchState(dir string) (*watchState, error) {
+	state := &watchState{}
+
+	data, err := os.ReadFile(filepath.Join(dir, watchStateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read watch state: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse watch state: %v", err)
+		}
+	}
+
+	if state.Channels == nil {
+		state.Channels = map[string]time.Time{}
+	}
+	if state.Processed == nil {
+		state.Processed = map[string]*watchVideo{}
+	}
+	if state.Pending == nil {
+		state.Pending = map[string]*watchVideo{}
+	}
+	return state, nil
+}
+
+// save writes the state atomically, so an interrupted write can't lose it
+func (s *watchState) save(dir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watch state: %v", err)
+	}
+
+	path := filepath.Join(dir, watchStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save watch state: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save watch state: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 0, "time between checks (default: watch.interval_minutes)")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "check once and exit, e.g. when run from cron")
+	watchCmd.Flags().StringVarP(&watchOutputDir, "output-dir", "d", "", "directory to write summaries to (default: watch.output_dir)")
+	watchCmd.Flags().StringVar(&watchFilenameFormat, "filename", "{{.VideoID}} - {{.Title}}.md", "output filename template (fields: .VideoID, .Title)")
+	watchCmd.Flags().BoolVar(&watchBackfill, "backfill", false, "also summarize uploads already in the feed when a channel is first watched")
+	watchCmd.Flags().DurationVar(&watchGiveUpAfter, "give-up-after", 48*time.Hour, "stop retrying a video this long after it was first seen")
+	watchCmd.Flags().IntVarP(&batchWorkers, "workers", "w", 4, "number of videos to process at once")
+	watchCmd.Flags().IntVar(&batchLLMConcurrency, "llm-concurrency", 0, "maximum concurrent LLM requests (default 1 for local providers, 4 otherwise)")
+	addStyleFlags(watchCmd)
+	addFetchFlags(watchCmd)
+}
@@ -0,0 +1,33 @@
+// Package cache stores fetched transcripts on disk under the user's cache directory.
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const cacheDirName = "yts"
+
+// Dir returns the yts cache directory, e.g. ~/.cache/yts on Linux
+func Dir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(userCacheDir, cacheDirName), nil
+}
+
+// safeName turns a cache key into a portable file name
+func safeName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.', r == ',':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
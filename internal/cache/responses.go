@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/conormkelly/yts-cli/internal/llm"
+)
+
+const (
+	responsesDirName  = "responses"
+	statsFileName     = "stats.json"
+	responseKeyPrefix = 2 // Characters of the key used to shard files into directories
+)
+
+// ResponseStats counts cache lookups across runs
+type ResponseStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// ResponseStore is an llm.ResponseCache backed by one JSON file per request hash
+type ResponseStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewResponseStore(cacheDir string) *ResponseStore {
+	return &ResponseStore{dir: filepath.Join(cacheDir, responsesDirName)}
+}
+
+func (s *ResponseStore) path(key string) string {
+	shard := key
+	if len(shard) > responseKeyPrefix {
+		shard = shard[:responseKeyPrefix]
+	}
+	return filepath.Join(s.dir, safeName(shard), safeName(key)+".json")
+}
+
+// Get returns the cached response for key and records a hit or miss
+func (s *ResponseStore) Get(key string) (*llm.CachedResponse, bool) {
+	var response llm.CachedResponse
+	data, err := os.ReadFile(s.path(key))
+	hit := err == nil && json.Unmarshal(data, &response) == nil
+
+	s.record(hit)
+	if !hit {
+		return nil, false
+	}
+	return &response, true
+}
+
+// Put stores a response, replacing any existing entry
+func (s *ResponseStore) Put(key string, response *llm.CachedResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached response: %w", err)
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+
+	return nil
+}
+
+// Usage returns the number of cached responses and their total size in bytes
+func (s *ResponseStore) Usage() (int, int64, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*", "*.json"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list cached responses: %w", err)
+	}
+
+	var size int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return len(paths), size, nil
+}
+
+// Stats returns the hit and miss counts recorded so far
+func (s *ResponseStore) Stats() ResponseStats {
+	var stats ResponseStats
+	if data, err := os.ReadFile(filepath.Join(s.dir, statsFileName)); err == nil {
+		_ = json.Unmarshal(data, &stats)
+	}
+	return stats
+}
+
+// Clear removes every cached response and resets the statistics
+func (s *ResponseStore) Clear() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("failed to clear response cache: %w", err)
+	}
+	return nil
+}
+
+// record updates the persisted hit/miss counters. Stats are informational,
+// so failures are ignored.
+func (s *ResponseStore) record(hit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.Stats()
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(s.dir, statsFileName), data, 0644)
+}
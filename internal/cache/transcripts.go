@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+const transcriptsDirName = "transcripts"
+
+// TranscriptEntry is a single cached transcript as stored on disk
+type TranscriptEntry struct {
+	VideoID    string                `json:"video_id"`
+	Key        string                `json:"key"`
+	FetchedAt  time.Time             `json:"fetched_at"`
+	Transcript transcript.Transcript `json:"transcript"`
+
+	Path string `json:"-"`
+	Size int64  `json:"-"`
+}
+
+// TranscriptStore is a transcript.Cache backed by one JSON file per video
+// and language selection. Entries older than the TTL are ignored.
+type TranscriptStore struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewTranscriptStore creates a store under cacheDir. A ttl of zero or less
+// keeps entries forever.
+func NewTranscriptStore(cacheDir string, ttl time.Duration) *TranscriptStore {
+	return &TranscriptStore{
+		dir: filepath.Join(cacheDir, transcriptsDirName),
+		ttl: ttl,
+	}
+}
+
+func (s *TranscriptStore) path(videoID, key string) string {
+	return filepath.Join(s.dir, safeName(videoID), safeName(key)+".json")
+}
+
+// Get returns the cached transcript if it exists and hasn't expired
+func (s *TranscriptStore) Get(videoID, key string) (*transcript.Transcript, bool) {
+	entry, err := readTranscriptEntry(s.path(videoID, key))
+	if err != nil || s.Expired(entry) {
+		return nil, false
+	}
+	return &entry.Transcript, true
+}
+
+// Put stores a transcript, replacing any existing entry
+func (s *TranscriptStore) Put(videoID, key string, t *transcript.Transcript) error {
+	entry := TranscriptEntry{
+		VideoID:    videoID,
+		Key:        key,
+		FetchedAt:  time.Now(),
+		Transcript: *t,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := s.path(videoID, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Expired reports whether an entry is older than the store's TTL
+func (s *TranscriptStore) Expired(entry *TranscriptEntry) bool {
+	return s.ttl > 0 && time.Since(entry.FetchedAt) > s.ttl
+}
+
+// List returns every cached entry, newest first. Unreadable files are skipped.
+func (s *TranscriptStore) List() ([]*TranscriptEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+
+	var entries []*TranscriptEntry
+	for _, path := range paths {
+		entry, err := readTranscriptEntry(path)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FetchedAt.After(entries[j].FetchedAt)
+	})
+
+	return entries, nil
+}
+
+// Clear removes every cached transcript
+func (s *TranscriptStore) Clear() error {
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("failed to clear transcript cache: %w", err)
+	}
+	return nil
+}
+
+// Prune removes expired entries and returns how many were removed
+func (s *TranscriptStore) Prune() (int, error) {
+	entries, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !s.Expired(entry) {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		removed++
+
+		// Drop the video's directory once it's empty
+		os.Remove(filepath.Dir(entry.Path))
+	}
+
+	return removed, nil
+}
+
+func readTranscriptEntry(path string) (*TranscriptEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry TranscriptEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	entry.Path = path
+	entry.Size = int64(len(data))
+
+	return &entry, nil
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+func TestTranscriptStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewTranscriptStore(t.TempDir(), 0)
+	english := &transcript.Transcript{VideoID: "dQw4w9WgXcQ", Title: "Test Video", Language: "en"}
+	french := &transcript.Transcript{VideoID: "dQw4w9WgXcQ", Title: "Test Video", Language: "fr", TranslatedBy: transcript.TranslatedByYouTube}
+
+	if _, ok := store.Get("dQw4w9WgXcQ", "en"); ok {
+		t.Fatal("Get() on an empty store found an entry")
+	}
+	for key, tr := range map[string]*transcript.Transcript{"en": english, "en>fr": french} {
+		if err := store.Put("dQw4w9WgXcQ", key, tr); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	// Each language selection is stored separately
+	for key, want := range map[string]string{"en": "en", "en>fr": "fr"} {
+		got, ok := store.Get("dQw4w9WgXcQ", key)
+		if !ok {
+			t.Errorf("Get(%s) found nothing", key)
+			continue
+		}
+		if got.Language != want || got.Title != "Test Video" {
+			t.Errorf("Get(%s) = %+v, want the %s transcript", key, got, want)
+		}
+	}
+	if _, ok := store.Get("dQw4w9WgXcQ", "de"); ok {
+		t.Error("Get(de) found an entry that was never stored")
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("List() = %d entries, want 2", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.VideoID != "dQw4w9WgXcQ" || entry.Path == "" || entry.Size == 0 {
+			t.Errorf("List() entry = %+v", entry)
+		}
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, ok := store.Get("dQw4w9WgXcQ", "en"); ok {
+		t.Error("Get() found an entry after Clear()")
+	}
+}
+
+func TestTranscriptStoreExpiry(t *testing.T) {
+	t.Parallel()
+
+	store := NewTranscriptStore(t.TempDir(), time.Hour)
+	if err := store.Put("fresh000001", "auto", &transcript.Transcript{Title: "Fresh"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("stale000001", "auto", &transcript.Transcript{Title: "Stale"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backdate one entry past the TTL
+	path := store.path("stale000001", "auto")
+	entry, err := readTranscriptEntry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.FetchedAt = time.Now().Add(-2 * time.Hour)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.Get("stale000001", "auto"); ok {
+		t.Error("Get() returned an expired entry")
+	}
+	if _, ok := store.Get("fresh000001", "auto"); !ok {
+		t.Error("Get() missed a fresh entry")
+	}
+
+	removed, err := store.Prune()
+	if err != nil || removed != 1 {
+		t.Errorf("Prune() = %d, %v, want 1 entry removed", removed, err)
+	}
+	if entries, _ := store.List(); len(entries) != 1 || entries[0].VideoID != "fresh000001" {
+		t.Errorf("List() after Prune() = %+v, want only the fresh entry", entries)
+	}
+}
@@ -0,0 +1,86 @@
+// Package chunk splits long transcripts into pieces that fit a model's
+// context window and summarizes them with a map-reduce pass.
+package chunk
+
+import (
+	"strings"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// charsPerToken is a rough average for English text across common tokenizers
+const charsPerToken = 4
+
+// Chunk is a contiguous run of transcript segments
+type Chunk struct {
+	Segments []transcript.TranscriptResponse
+}
+
+// Text joins the chunk's segments, one per line
+func (c Chunk) Text() string {
+	var text strings.Builder
+	for _, segment := range c.Segments {
+		text.WriteString(segment.Text + "\n")
+	}
+	return text.String()
+}
+
+// Start returns the start time of the first segment in seconds
+func (c Chunk) Start() float64 {
+	if len(c.Segments) == 0 {
+		return 0
+	}
+	return c.Segments[0].Start
+}
+
+// EstimateTokens approximates the number of tokens in text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Split groups segments into chunks of at most maxTokens estimated tokens,
+// never splitting a segment. Consecutive chunks share trailing segments
+// worth up to overlapTokens so context isn't lost at the boundaries.
+// A maxTokens of zero or less returns all segments as a single chunk.
+func Split(segments []transcript.TranscriptResponse, maxTokens, overlapTokens int) []Chunk {
+	if maxTokens <= 0 || len(segments) == 0 {
+		return []Chunk{{Segments: segments}}
+	}
+
+	var chunks []Chunk
+	start := 0
+	for start < len(segments) {
+		// Take segments until the budget is reached, but always at least one
+		end := start
+		tokens := 0
+		for end < len(segments) {
+			segmentTokens := EstimateTokens(segments[end].Text + "\n")
+			if end > start && tokens+segmentTokens > maxTokens {
+				break
+			}
+			tokens += segmentTokens
+			end++
+		}
+
+		chunks = append(chunks, Chunk{Segments: segments[start:end]})
+		if end >= len(segments) {
+			break
+		}
+
+		// Step back over trailing segments so the next chunk overlaps this one,
+		// while always moving forward by at least one segment
+		next := end
+		overlap := 0
+		for next > start+1 {
+			segmentTokens := EstimateTokens(segments[next-1].Text + "\n")
+			if overlap+segmentTokens > overlapTokens {
+				break
+			}
+			overlap += segmentTokens
+			next--
+		}
+		start = next
+	}
+
+	return chunks
+}
@@ -0,0 +1,154 @@
+package chunk
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// testSegments returns n segments of exactly two estimated tokens each,
+// starting 10 seconds apart
+func testSegments(n int) []transcript.TranscriptResponse {
+	segments := make([]transcript.TranscriptResponse, n)
+	for i := range segments {
+		segments[i] = transcript.TranscriptResponse{Text: fmt.Sprintf("seg%04d", i), Start: float64(i * 10)}
+	}
+	return segments
+}
+
+// segmentTexts lists the text of each chunk's segments
+func segmentTexts(chunks []Chunk) [][]string {
+	result := make([][]string, len(chunks))
+	for i, c := range chunks {
+		result[i] = []string{}
+		for _, segment := range c.Segments {
+			result[i] = append(result[i], segment.Text)
+		}
+	}
+	return result
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for text, want := range map[string]int{"": 0, "a": 1, "abcd": 1, "abcde": 2, "seg0000\n": 2} {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		segments  int
+		maxTokens int
+		overlap   int
+		want      [][]string
+	}{
+		{
+			name:      "no limit",
+			segments:  4,
+			maxTokens: 0,
+			want:      [][]string{{"seg0000", "seg0001", "seg0002", "seg0003"}},
+		},
+		{
+			name:      "fits in one chunk",
+			segments:  3,
+			maxTokens: 6,
+			want:      [][]string{{"seg0000", "seg0001", "seg0002"}},
+		},
+		{
+			name:      "no overlap",
+			segments:  7,
+			maxTokens: 6,
+			want: [][]string{
+				{"seg0000", "seg0001", "seg0002"},
+				{"seg0003", "seg0004", "seg0005"},
+				{"seg0006"},
+			},
+		},
+		{
+			name:      "budget between segment boundaries",
+			segments:  5,
+			maxTokens: 5,
+			want: [][]string{
+				{"seg0000", "seg0001"},
+				{"seg0002", "seg0003"},
+				{"seg0004"},
+			},
+		},
+		{
+			name:      "one segment of overlap",
+			segments:  7,
+			maxTokens: 6,
+			overlap:   2,
+			want: [][]string{
+				{"seg0000", "seg0001", "seg0002"},
+				{"seg0002", "seg0003", "seg0004"},
+				{"seg0004", "seg0005", "seg0006"},
+			},
+		},
+		{
+			name:      "overlap smaller than a segment",
+			segments:  4,
+			maxTokens: 4,
+			overlap:   1,
+			want: [][]string{
+				{"seg0000", "seg0001"},
+				{"seg0002", "seg0003"},
+			},
+		},
+		{
+			name:      "overlap as large as the chunk still moves forward",
+			segments:  4,
+			maxTokens: 4,
+			overlap:   100,
+			want: [][]string{
+				{"seg0000", "seg0001"},
+				{"seg0001", "seg0002"},
+				{"seg0002", "seg0003"},
+			},
+		},
+		{
+			name:      "segments larger than the budget aren't split",
+			segments:  3,
+			maxTokens: 1,
+			want:      [][]string{{"seg0000"}, {"seg0001"}, {"seg0002"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(testSegments(tt.segments), tt.maxTokens, tt.overlap)
+			if got := segmentTexts(chunks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %v, want %v", got, tt.want)
+			}
+			for i, c := range chunks {
+				if tokens := EstimateTokens(c.Text()); tt.maxTokens > 0 && len(c.Segments) > 1 && tokens > tt.maxTokens {
+					t.Errorf("chunk %d has %d tokens, over the budget of %d", i, tokens, tt.maxTokens)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitEmpty(t *testing.T) {
+	chunks := Split(nil, 100, 10)
+	if len(chunks) != 1 || len(chunks[0].Segments) != 0 {
+		t.Errorf("Split(nil) = %v, want one empty chunk", chunks)
+	}
+}
+
+func TestChunkTextAndStart(t *testing.T) {
+	c := Chunk{Segments: testSegments(3)[1:]}
+	if got, want := c.Text(), "seg0001\nseg0002\n"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got := c.Start(); got != 10 {
+		t.Errorf("Start() = %v, want 10", got)
+	}
+	if got := (Chunk{}).Start(); got != 0 {
+		t.Errorf("Start() of an empty chunk = %v, want 0", got)
+	}
+}
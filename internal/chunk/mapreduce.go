@@ -0,0 +1,142 @@
+package chunk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/internal/constants"
+	"github.com/conormkelly/yts-cli/internal/llm"
+	"github.com/conormkelly/yts-cli/internal/prompt"
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// MapReduceOptions configures a map-reduce summarization
+type MapReduceOptions struct {
+	// MapPrompt is the system prompt used to summarize each chunk
+	MapPrompt string
+	// ReducePrompt explains to the model that its input is a set of section summaries
+	ReducePrompt string
+	// SummaryPrompt is the configured summary prompt applied in the reduce pass
+	SummaryPrompt string
+	// MaxTokens bounds the input of each reduce pass; zero or less always
+	// reduces in a single pass
+	MaxTokens int
+	// OnProgress is called before each chunk is summarized
+	OnProgress func(current, total int)
+}
+
+// MapReduce summarizes each chunk independently, then combines the partial
+// summaries with Reduce. Only the final pass is streamed to callback.
+func MapReduce(ctx context.Context, provider llm.Provider, chunks []Chunk, opts MapReduceOptions, callback func(string)) error {
+	partials := make([]string, 0, len(chunks))
+	for i, c := range chunks {
+		if opts.OnProgress != nil {
+			opts.OnProgress(i+1, len(chunks))
+		}
+
+		input := fmt.Sprintf("Section %d of %d (starting at %s):\n\n%s",
+			i+1, len(chunks), prompt.FormatTimestamp(c.Start()), c.Text())
+
+		var partial strings.Builder
+		err := provider.Stream(ctx, opts.MapPrompt, input, func(chunk string) {
+			partial.WriteString(chunk)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to summarize section %d of %d: %w", i+1, len(chunks), err)
+		}
+		partials = append(partials, fmt.Sprintf("Section %d summary:\n%s", i+1, strings.TrimSpace(partial.String())))
+	}
+
+	err := Reduce(ctx, provider, partials, ReduceOptions{
+		GroupPrompt: constants.SectionGroupPrompt,
+		Prompt:      opts.ReducePrompt + "\n\n" + opts.SummaryPrompt,
+		MaxTokens:   opts.MaxTokens,
+	}, callback)
+	if err != nil {
+		return fmt.Errorf("failed to combine section summaries: %w", err)
+	}
+	return nil
+}
+
+// ReduceOptions configures how Reduce combines its inputs
+type ReduceOptions struct {
+	// GroupPrompt is the system prompt used to condense a group of inputs
+	// that together exceed MaxTokens
+	GroupPrompt string
+	// Prompt is the system prompt for the final pass
+	Prompt string
+	// MaxTokens bounds the input of each pass; zero or less always uses a
+	// single pass
+	MaxTokens int
+}
+
+// Reduce combines inputs in a final pass streamed to callback. While the
+// inputs together exceed opts.MaxTokens, runs of consecutive inputs that fit
+// are condensed into one with opts.GroupPrompt, repeating until they fit.
+func Reduce(ctx context.Context, provider llm.Provider, inputs []string, opts ReduceOptions, callback func(string)) error {
+	for round := 1; opts.MaxTokens > 0 && len(inputs) > 1 && EstimateTokens(joinInputs(inputs)) > opts.MaxTokens; round++ {
+		groups := groupInputs(inputs, opts.MaxTokens)
+		condensed := make([]string, 0, len(groups))
+		for i, group := range groups {
+			if len(group) == 1 {
+				condensed = append(condensed, group[0])
+				continue
+			}
+
+			var output strings.Builder
+			err := provider.Stream(ctx, opts.GroupPrompt, joinInputs(group), func(chunk string) {
+				output.WriteString(chunk)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to condense group %d of %d in round %d: %w", i+1, len(groups), round, err)
+			}
+			condensed = append(condensed, strings.TrimSpace(output.String()))
+		}
+		inputs = condensed
+	}
+
+	return provider.Stream(ctx, opts.Prompt, joinInputs(inputs), callback)
+}
+
+// groupInputs splits inputs into runs whose joined text fits maxTokens.
+// Each run but the last has at least two inputs, so every round of Reduce
+// leaves fewer inputs than it started with.
+func groupInputs(inputs []string, maxTokens int) [][]string {
+	var groups [][]string
+	start := 0
+	for start < len(inputs) {
+		end := start + 1
+		for end < len(inputs) && (end-start < 2 || EstimateTokens(joinInputs(inputs[start:end+1])) <= maxTokens) {
+			end++
+		}
+		groups = append(groups, inputs[start:end])
+		start = end
+	}
+	return groups
+}
+
+// joinInputs joins reduce inputs into the text of a single request
+func joinInputs(inputs []string) string {
+	return strings.Join(inputs, "\n\n")
+}
+
+// Summarize streams a summary of the transcript to callback. Transcripts
+// longer than chunking.ChunkTokens are summarized with MapReduce, calling
+// onProgress (if set) before each section.
+func Summarize(ctx context.Context, provider llm.Provider, t *transcript.Transcript, systemPrompt string, chunking config.ChunkingConfig, onProgress func(current, total int), callback func(string)) error {
+	text := t.Text()
+	if chunking.ChunkTokens <= 0 || EstimateTokens(text) <= chunking.ChunkTokens {
+		return provider.Stream(ctx, systemPrompt, text, callback)
+	}
+
+	chunks := Split(t.Entries, chunking.ChunkTokens, chunking.OverlapTokens)
+	return MapReduce(ctx, provider, chunks, MapReduceOptions{
+		MapPrompt:     constants.ChunkSummaryPrompt,
+		ReducePrompt:  chunking.ReducePrompt,
+		SummaryPrompt: systemPrompt,
+		MaxTokens:     chunking.ChunkTokens,
+		OnProgress:    onProgress,
+	}, callback)
+}
@@ -0,0 +1,277 @@
+package chunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/constants"
+	"github.com/conormkelly/yts-cli/internal/llm"
+)
+
+// call is a request made to a stubProvider
+type call struct {
+	system string
+	input  string
+}
+
+// stubProvider answers each request with reply, recording the requests
+type stubProvider struct {
+	reply func(system, input string) (string, error)
+	calls []call
+}
+
+func (p *stubProvider) Generate(ctx context.Context, r *llm.Request, callback func(string)) (*llm.Result, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *stubProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	p.calls = append(p.calls, call{system: systemPrompt, input: transcript})
+	output, err := p.reply(systemPrompt, transcript)
+	if err != nil {
+		return err
+	}
+	// Stream in two pieces, as real providers do
+	half := len(output) / 2
+	callback(output[:half])
+	callback(output[half:])
+	return nil
+}
+
+// callsWith returns the calls made with the system prompt system
+func (p *stubProvider) callsWith(system string) []call {
+	var result []call
+	for _, c := range p.calls {
+		if c.system == system {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func TestMapReduceSinglePass(t *testing.T) {
+	provider := &stubProvider{reply: func(system, input string) (string, error) {
+		if system == "map" {
+			return fmt.Sprintf("  partial %d  ", strings.Count(input, "seg")), nil
+		}
+		return "final summary", nil
+	}}
+
+	chunks := Split(testSegments(100)[:7], 6, 0) // 3, 3 and 1 segments
+	var progress []int
+	var output strings.Builder
+	err := MapReduce(context.Background(), provider, chunks, MapReduceOptions{
+		MapPrompt:     "map",
+		ReducePrompt:  "reduce",
+		SummaryPrompt: "summarize",
+		OnProgress: func(current, total int) {
+			if total != 3 {
+				t.Errorf("OnProgress total = %d, want 3", total)
+			}
+			progress = append(progress, current)
+		},
+	}, func(text string) {
+		output.WriteString(text)
+	})
+	if err != nil {
+		t.Fatalf("MapReduce() error = %v", err)
+	}
+
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+	if output.String() != "final summary" {
+		t.Errorf("output = %q, want only the final pass", output.String())
+	}
+
+	maps := provider.callsWith("map")
+	if len(maps) != 3 {
+		t.Fatalf("map calls = %d, want 3", len(maps))
+	}
+	if want := "Section 2 of 3 (starting at 0:30):\n\nseg0003\n"; !strings.HasPrefix(maps[1].input, want) {
+		t.Errorf("map input = %q, want it to start with %q", maps[1].input, want)
+	}
+
+	reduces := provider.callsWith("reduce\n\nsummarize")
+	if len(reduces) != 1 || len(provider.calls) != 4 {
+		t.Fatalf("calls = %+v, want 3 maps and 1 reduce", provider.calls)
+	}
+	want := "Section 1 summary:\npartial 3\n\nSection 2 summary:\npartial 3\n\nSection 3 summary:\npartial 1"
+	if reduces[0].input != want {
+		t.Errorf("reduce input = %q, want %q", reduces[0].input, want)
+	}
+}
+
+func TestMapReduceRecursive(t *testing.T) {
+	provider := &stubProvider{reply: func(system, input string) (string, error) {
+		switch system {
+		case "map":
+			return strings.Repeat("p", 60), nil
+		case constants.SectionGroupPrompt:
+			return strings.Repeat("g", 20), nil
+		default:
+			return "final", nil
+		}
+	}}
+
+	// Each labeled partial is 79 characters, or 20 tokens, so only two fit
+	// in each group and the four condensed groups then fit in one pass
+	const maxTokens = 45
+	chunks := Split(testSegments(8), 2, 0)
+	var output strings.Builder
+	err := MapReduce(context.Background(), provider, chunks, MapReduceOptions{
+		MapPrompt:     "map",
+		ReducePrompt:  "reduce",
+		SummaryPrompt: "summarize",
+		MaxTokens:     maxTokens,
+	}, func(text string) {
+		output.WriteString(text)
+	})
+	if err != nil {
+		t.Fatalf("MapReduce() error = %v", err)
+	}
+	if output.String() != "final" {
+		t.Errorf("output = %q, want final", output.String())
+	}
+
+	groups := provider.callsWith(constants.SectionGroupPrompt)
+	if len(groups) != 4 {
+		t.Fatalf("group calls = %d, want 4", len(groups))
+	}
+	if !strings.HasPrefix(groups[1].input, "Section 3 summary:") || !strings.Contains(groups[1].input, "Section 4 summary:") {
+		t.Errorf("second group input = %q, want sections 3 and 4", groups[1].input)
+	}
+	for i, group := range groups {
+		if tokens := EstimateTokens(group.input); tokens > maxTokens {
+			t.Errorf("group %d input has %d tokens, over the budget of %d", i+1, tokens, maxTokens)
+		}
+	}
+
+	final := provider.callsWith("reduce\n\nsummarize")
+	if len(final) != 1 {
+		t.Fatalf("final calls = %d, want 1", len(final))
+	}
+	if want := strings.TrimSuffix(strings.Repeat(strings.Repeat("g", 20)+"\n\n", 4), "\n\n"); final[0].input != want {
+		t.Errorf("final input = %q, want %q", final[0].input, want)
+	}
+}
+
+func TestReduceRounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputs     []string
+		maxTokens  int
+		condensed  string // Reply to each group call
+		wantGroups int
+		wantFinal  string
+	}{
+		{
+			name:      "no budget",
+			inputs:    []string{strings.Repeat("a", 400), strings.Repeat("b", 400)},
+			maxTokens: 0,
+			wantFinal: strings.Repeat("a", 400) + "\n\n" + strings.Repeat("b", 400),
+		},
+		{
+			name:      "fits",
+			inputs:    []string{"one", "two"},
+			maxTokens: 10,
+			wantFinal: "one\n\ntwo",
+		},
+		{
+			name:      "a single input is never condensed",
+			inputs:    []string{strings.Repeat("a", 400)},
+			maxTokens: 10,
+			wantFinal: strings.Repeat("a", 400),
+		},
+		{
+			// Every input is over the budget, so they are condensed in pairs,
+			// then the pair with the leftover input, until one remains
+			name:       "inputs over the budget",
+			inputs:     []string{strings.Repeat("a", 100), strings.Repeat("b", 100), strings.Repeat("c", 100)},
+			maxTokens:  10,
+			condensed:  strings.Repeat("x", 60),
+			wantGroups: 2,
+			wantFinal:  strings.Repeat("x", 60),
+		},
+		{
+			name:       "several rounds",
+			inputs:     []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd", "eeeeeeee", "ffffffff", "gggggggg", "hhhhhhhh"},
+			maxTokens:  5,
+			condensed:  "xxxxxxx",
+			wantGroups: 6, // 8 inputs to 4, then 2, which fit
+			wantFinal:  "xxxxxxx\n\nxxxxxxx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &stubProvider{reply: func(system, input string) (string, error) {
+				if system == "group" {
+					return tt.condensed, nil
+				}
+				return "done", nil
+			}}
+
+			err := Reduce(context.Background(), provider, tt.inputs, ReduceOptions{
+				GroupPrompt: "group",
+				Prompt:      "final",
+				MaxTokens:   tt.maxTokens,
+			}, func(string) {})
+			if err != nil {
+				t.Fatalf("Reduce() error = %v", err)
+			}
+
+			if got := len(provider.callsWith("group")); got != tt.wantGroups {
+				t.Errorf("group calls = %d, want %d", got, tt.wantGroups)
+			}
+			final := provider.callsWith("final")
+			if len(final) != 1 {
+				t.Fatalf("final calls = %d, want 1", len(final))
+			}
+			if final[0].input != tt.wantFinal {
+				t.Errorf("final input = %q, want %q", final[0].input, tt.wantFinal)
+			}
+		})
+	}
+}
+
+func TestMapReduceErrors(t *testing.T) {
+	failing := errors.New("server unavailable")
+
+	tests := []struct {
+		name    string
+		failOn  string
+		wantErr string
+	}{
+		{name: "map", failOn: "map", wantErr: "failed to summarize section 1 of 4"},
+		{name: "group", failOn: constants.SectionGroupPrompt, wantErr: "failed to condense group 1 of 2 in round 1"},
+		{name: "final", failOn: "reduce\n\nsummarize", wantErr: "failed to combine section summaries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &stubProvider{reply: func(system, input string) (string, error) {
+				if system == tt.failOn {
+					return "", failing
+				}
+				return strings.Repeat("p", 60), nil
+			}}
+
+			err := MapReduce(context.Background(), provider, Split(testSegments(4), 2, 0), MapReduceOptions{
+				MapPrompt:     "map",
+				ReducePrompt:  "reduce",
+				SummaryPrompt: "summarize",
+				MaxTokens:     45,
+			}, func(string) {})
+			if !errors.Is(err, failing) {
+				t.Errorf("MapReduce() error = %v, want it to wrap %v", err, failing)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("MapReduce() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
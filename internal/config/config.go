@@ -2,25 +2,34 @@ package config
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/conormkelly/yts-cli/internal/constants"
-	"github.com/spf13/viper"
 )
 
 // Current config value
-const currentConfigVersion = "1.0.1"
+const currentConfigVersion = "1.3.0"
 
 // Config holds all configuration values
 type Config struct {
 	Version     string           `mapstructure:"version"`
-	Provider    string           `mapstructure:"provider"` // Current provider (lmstudio, ollama)
+	Provider    string           `mapstructure:"provider"` // Current provider: a built-in provider or an endpoint name
+	Profile     string           `mapstructure:"profile"`  // Active profile, empty for none
+	Profiles    ProfileConfig    `mapstructure:"profiles"`
 	Providers   ProvidersConfig  `mapstructure:"providers"`
+	Endpoints   EndpointConfig   `mapstructure:"endpoints"`
 	Summaries   SummaryConfig    `mapstructure:"summaries"`
 	Transcripts TranscriptConfig `mapstructure:"transcripts"`
 	Queries     QueryConfig      `mapstructure:"queries"`
+	Chat        ChatConfig       `mapstructure:"chat"`
+	Cache       CacheConfig      `mapstructure:"cache"`
+	Watch       WatchConfig      `mapstructure:"watch"`
+	Secrets     SecretsConfig    `mapstructure:"secrets"`
+
+	dir          string        // Directory of the config file it was loaded from
+	secretStores []SecretStore // Replace the stores in Secrets when set
 }
 
 // ProvidersConfig holds settings for each provider
@@ -29,43 +38,82 @@ type ProvidersConfig struct {
 	Ollama   OllamaConfig   `mapstructure:"ollama"`
 	Claude   ClaudeConfig   `mapstructure:"claude"`
 	OpenAI   OpenAIConfig   `mapstructure:"openai"`
+	Gemini   GeminiConfig   `mapstructure:"gemini"`
 }
 
 type LMStudioConfig struct {
-	BaseURL string `mapstructure:"base_url"`
-	Model   string `mapstructure:"model"`
+	BaseURL     string         `mapstructure:"base_url"`
+	Model       string         `mapstructure:"model"`
+	Temperature *float64       `mapstructure:"temperature,omitempty"` // Unset uses the server's default
+	Chunking    ChunkingConfig `mapstructure:"chunking"`
 }
 
 type OllamaConfig struct {
-	BaseURL string `mapstructure:"base_url"`
-	Model   string `mapstructure:"model"`
+	BaseURL     string         `mapstructure:"base_url"`
+	Model       string         `mapstructure:"model"`
+	Temperature *float64       `mapstructure:"temperature,omitempty"` // Unset uses the model's default
+	Chunking    ChunkingConfig `mapstructure:"chunking"`
 }
 
 type ClaudeConfig struct {
-	Model       string  `mapstructure:"model"`
-	Temperature float64 `mapstructure:"temperature"`
-	MaxTokens   int     `mapstructure:"max_tokens"`
-	TimeoutSecs int     `mapstructure:"timeout_seconds"`
-	MaxRetries  int     `mapstructure:"max_retries"`
+	BaseURL     string            `mapstructure:"base_url"` // API root; /v1/messages is appended
+	Headers     map[string]string `mapstructure:"headers"`  // Sent with every request, e.g. for gateway auth
+	Model       string            `mapstructure:"model"`
+	Temperature float64           `mapstructure:"temperature"`
+	MaxTokens   int               `mapstructure:"max_tokens"`
+	TimeoutSecs int               `mapstructure:"timeout_seconds"`
+	MaxRetries  int               `mapstructure:"max_retries"`
+
+	Chunking ChunkingConfig `mapstructure:"chunking"`
 }
 
 type OpenAIConfig struct {
-	Model       string  `mapstructure:"model"`
-	Temperature float64 `mapstructure:"temperature"`
-	MaxTokens   int     `mapstructure:"max_tokens"`
-	TimeoutSecs int     `mapstructure:"timeout_seconds"`
-	MaxRetries  int     `mapstructure:"max_retries"`
-	OrgID       string  `mapstructure:"organization_id"`
+	BaseURL     string            `mapstructure:"base_url"` // API root; /chat/completions is appended
+	Headers     map[string]string `mapstructure:"headers"`  // Sent with every request, e.g. for gateway auth
+	Model       string            `mapstructure:"model"`
+	Temperature float64           `mapstructure:"temperature"`
+	MaxTokens   int               `mapstructure:"max_tokens"`
+	TimeoutSecs int               `mapstructure:"timeout_seconds"`
+	MaxRetries  int               `mapstructure:"max_retries"`
+	OrgID       string            `mapstructure:"organization_id"`
+
+	Chunking ChunkingConfig `mapstructure:"chunking"`
 }
 
-// SummaryConfig holds the different summary templates
-type SummaryConfig struct {
-	Short SummaryTemplate `mapstructure:"short"`
-	Long  SummaryTemplate `mapstructure:"long"`
+type GeminiConfig struct {
+	BaseURL         string  `mapstructure:"base_url"` // API root; /models/<model>:streamGenerateContent is appended
+	Model           string  `mapstructure:"model"`
+	Temperature     float64 `mapstructure:"temperature"`
+	MaxOutputTokens int     `mapstructure:"max_output_tokens"`
+	TimeoutSecs     int     `mapstructure:"timeout_seconds"`
+	MaxRetries      int     `mapstructure:"max_retries"`
+
+	Chunking ChunkingConfig `mapstructure:"chunking"`
 }
 
-type SummaryTemplate struct {
-	SystemPrompt string `mapstructure:"system_prompt"`
+// ChunkingConfig controls map-reduce summarization of transcripts that
+// don't fit in a provider's context window
+type ChunkingConfig struct {
+	ChunkTokens   int    `mapstructure:"chunk_tokens"` // 0 disables chunking
+	OverlapTokens int    `mapstructure:"overlap_tokens"`
+	ReducePrompt  string `mapstructure:"reduce_prompt"`
+}
+
+// SummaryConfig maps style names, such as "short" and "long", to summary styles
+type SummaryConfig map[string]SummaryStyle
+
+// SummaryStyle is a named summary prompt. The optional overrides change the
+// provider settings used for this style only.
+type SummaryStyle struct {
+	SystemPrompt string   `mapstructure:"system_prompt"`
+	Provider     string   `mapstructure:"provider,omitempty"`
+	Model        string   `mapstructure:"model,omitempty"`
+	Temperature  *float64 `mapstructure:"temperature,omitempty"`
+}
+
+// HasOverrides reports whether the style changes any provider settings
+func (s SummaryStyle) HasOverrides() bool {
+	return s.Provider != "" || s.Model != "" || s.Temperature != nil
 }
 
 type TranscriptConfig struct {
@@ -77,128 +125,81 @@ type QueryConfig struct {
 	SystemPrompt string `mapstructure:"system_prompt"`
 }
 
+// ChatConfig holds the template for interactive chat sessions
+type ChatConfig struct {
+	SystemPrompt string `mapstructure:"system_prompt"`
+}
+
+// CacheConfig controls the on-disk transcript cache
+type CacheConfig struct {
+	TranscriptTTLHours int  `mapstructure:"transcript_ttl_hours"` // 0 disables the cache
+	Responses          bool `mapstructure:"responses"`            // Opt-in caching of LLM responses
+}
+
+// SecretsConfig controls where API keys are looked up and stored
+type SecretsConfig struct {
+	Order []string `mapstructure:"order"` // Stores searched for API keys, in order: env, keyring, file
+	Store string   `mapstructure:"store"` // Store that yts apikey set writes to: keyring or file
+}
+
+// WatchConfig controls yts watch
+type WatchConfig struct {
+	FeedBaseURL     string   `mapstructure:"feed_base_url"`
+	Channels        []string `mapstructure:"channels"`
+	IntervalMinutes int      `mapstructure:"interval_minutes"`
+	OutputDir       string   `mapstructure:"output_dir"`
+}
+
 const (
 	// Global
 	defaultProvider = "lmstudio"
-	configFileName  = "config"
-	configFileType  = "json"
+	configFileName  = "config.json"
 	configDirName   = "yts"
 
-	defaultLMStudioURL   = "http://localhost:1234"
-	defaultLMStudioModel = "llama-3.2-3b-instruct"
+	defaultLMStudioURL         = "http://localhost:1234"
+	defaultLMStudioModel       = "llama-3.2-3b-instruct"
+	defaultLMStudioChunkTokens = 6000 // Leaves room for the prompt and output in an 8k context
 
-	defaultOllamaURL   = "http://localhost:11434"
-	defaultOllamaModel = "llama3.2"
+	defaultOllamaURL         = "http://localhost:11434"
+	defaultOllamaModel       = "llama3.2"
+	defaultOllamaChunkTokens = 3000 // Ollama defaults to a small context window
 
+	defaultClaudeBaseURL        = "https://api.anthropic.com"
 	defaultClaudeModel          = "claude-3-5-sonnet-20241022"
 	defaultClaudeTemperature    = 0.3  // Lower for more focused summaries
 	defaultClaudeMaxTokens      = 8192 // Generous limit for detailed analysis
 	defaultClaudeTimeoutSeconds = 120  // Long timeout for big transcripts
 	defaultClaudeMaxRetries     = 3
+	defaultClaudeChunkTokens    = 150000
 
+	defaultOpenAIBaseURL        = "https://api.openai.com/v1"
 	defaultOpenAIModel          = "gpt-4o" // Latest model for best summaries
 	defaultOpenAITemperature    = 0.3      // Lower for more focused summaries
 	defaultOpenAIMaxTokens      = 8192     // Conservative limit for most transcripts
 	defaultOpenAITimeoutSeconds = 120      // Long timeout for big transcripts
 	defaultOpenAIMaxRetries     = 3
-)
-
-// Initialize sets up Viper with our configuration
-func Initialize() error {
-	// Get user config directory
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return fmt.Errorf("failed to get config directory: %w", err)
-	}
-
-	// Ensure our config directory exists
-	ytsConfigDir := filepath.Join(configDir, configDirName)
-	if err := os.MkdirAll(ytsConfigDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Set up Viper
-	viper.SetConfigName(configFileName)
-	viper.SetConfigType(configFileType)
-	viper.AddConfigPath(ytsConfigDir)
-
-	// Set defaults
-	setDefaults()
-
-	// Bind environment variables
-	bindEnvVars()
+	defaultOpenAIChunkTokens    = 100000
 
-	// Try to read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file doesn't exist, create it with defaults
-			setDefaults()
-			viper.Set("version", currentConfigVersion)
+	defaultGeminiBaseURL         = "https://generativelanguage.googleapis.com/v1beta"
+	defaultGeminiModel           = "gemini-1.5-pro"
+	defaultGeminiTemperature     = 0.3  // Lower for more focused summaries
+	defaultGeminiMaxOutputTokens = 8192 // The most Gemini 1.5 models will generate
+	defaultGeminiTimeoutSeconds  = 120  // Long timeout for big transcripts
+	defaultGeminiMaxRetries      = 3
+	defaultGeminiChunkTokens     = 500000 // Well within the 2M token context window
 
-			configPath := filepath.Join(ytsConfigDir, configFileName+"."+configFileType)
-			if err := viper.SafeWriteConfigAs(configPath); err != nil {
-				return fmt.Errorf("failed to write default config: %w", err)
-			}
-		} else {
-			return fmt.Errorf("failed to read config: %w", err)
-		}
-	} else {
-		// Config exists, check for migrations
-		var cfg Config
-		if err := viper.Unmarshal(&cfg); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-
-		// Apply migrations if needed
-		if cfg.Version != currentConfigVersion {
-			applyMigrations(&cfg)
+	defaultChunkOverlapTokens = 200
 
-			// Update viper with migrated config
-			for k, v := range structToMap(cfg) {
-				viper.Set(k, v)
-			}
-
-			// Save the updated config
-			if err := viper.WriteConfig(); err != nil {
-				return fmt.Errorf("failed to save migrated config: %w", err)
-			}
+	defaultTranscriptCacheTTLHours = 7 * 24 // Captions rarely change once published
 
-			fmt.Fprintf(os.Stderr, "Configuration updated to version %s\n", currentConfigVersion)
-		}
-	}
-
-	return nil
-}
-
-// applyMigrations updates an existing config to the current version without losing user settings
-func applyMigrations(cfg *Config) {
-	// If there's no version or it's a new config, just set the current version
-	if cfg.Version == "" {
-		cfg.Version = currentConfigVersion
-		return
-	}
-
-	// Handle specific version migrations
-	switch cfg.Version {
-	case "1.0.0":
-		// Migrate from 1.0.0 to 1.1.0
-		if cfg.Queries.SystemPrompt == "" {
-			cfg.Queries = QueryConfig{
-				SystemPrompt: constants.QueryPrompt,
-			}
-		}
-		cfg.Version = "1.1.0"
-		// fallthrough
-
-		// Add future version migrations here with fallthrough to apply sequentially
-		// case "1.1.0":
-		//    // Migrate from 1.1.0 to 1.2.0
-		//    cfg.Version = "1.2.0"
-		//    fallthrough
-	}
-}
+	defaultWatchFeedBaseURL     = "https://www.youtube.com/feeds/videos.xml"
+	defaultWatchIntervalMinutes = 30
+	defaultWatchOutputDir       = "~/yts-watch"
+)
 
-// Helper function to convert struct to map for viper
+// structToMap flattens a struct into dotted mapstructure keys, for viper
+// defaults. Maps of structs are flattened by key, and empty fields tagged
+// omitempty are left out.
 func structToMap(obj interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 	val := reflect.ValueOf(obj)
@@ -209,111 +210,214 @@ func structToMap(obj interface{}) map[string]interface{} {
 
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
-		tag := typ.Field(i).Tag.Get("mapstructure")
-		if tag != "" && tag != "-" {
-			if field.Kind() == reflect.Struct {
-				// Handle nested structs recursively
-				nested := structToMap(field.Interface())
-				for k, v := range nested {
-					result[tag+"."+k] = v
+		tag, opts, _ := strings.Cut(typ.Field(i).Tag.Get("mapstructure"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if opts == "omitempty" && field.IsZero() {
+			continue
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			// Handle nested structs recursively
+			for k, v := range structToMap(field.Interface()) {
+				result[tag+"."+k] = v
+			}
+		case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Struct:
+			iter := field.MapRange()
+			for iter.Next() {
+				for k, v := range structToMap(iter.Value().Interface()) {
+					result[tag+"."+iter.Key().String()+"."+k] = v
 				}
-			} else {
-				result[tag] = field.Interface()
 			}
+		case field.Kind() == reflect.Ptr:
+			if !field.IsNil() {
+				result[tag] = field.Elem().Interface()
+			}
+		default:
+			result[tag] = field.Interface()
 		}
 	}
 
 	return result
 }
 
-// GetConfig returns the current configuration
-func GetConfig() (*Config, error) {
-	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// Default returns the built-in configuration, without reading the config
+// file or environment. It is the base that user settings are layered on.
+func Default() *Config {
+	chunking := func(chunkTokens int) ChunkingConfig {
+		return ChunkingConfig{
+			ChunkTokens:   chunkTokens, // Sized to each provider's typical context window
+			OverlapTokens: defaultChunkOverlapTokens,
+			ReducePrompt:  constants.ReducePrompt,
+		}
+	}
+
+	return &Config{
+		Version:  currentConfigVersion,
+		Provider: defaultProvider,
+		Providers: ProvidersConfig{
+			LMStudio: LMStudioConfig{
+				BaseURL:  defaultLMStudioURL,
+				Model:    defaultLMStudioModel,
+				Chunking: chunking(defaultLMStudioChunkTokens),
+			},
+			Ollama: OllamaConfig{
+				BaseURL:  defaultOllamaURL,
+				Model:    defaultOllamaModel,
+				Chunking: chunking(defaultOllamaChunkTokens),
+			},
+			Claude: ClaudeConfig{
+				BaseURL:     defaultClaudeBaseURL,
+				Headers:     map[string]string{},
+				Model:       defaultClaudeModel,
+				Temperature: defaultClaudeTemperature,
+				MaxTokens:   defaultClaudeMaxTokens,
+				TimeoutSecs: defaultClaudeTimeoutSeconds,
+				MaxRetries:  defaultClaudeMaxRetries,
+				Chunking:    chunking(defaultClaudeChunkTokens),
+			},
+			OpenAI: OpenAIConfig{
+				BaseURL:     defaultOpenAIBaseURL,
+				Headers:     map[string]string{},
+				Model:       defaultOpenAIModel,
+				Temperature: defaultOpenAITemperature,
+				MaxTokens:   defaultOpenAIMaxTokens,
+				TimeoutSecs: defaultOpenAITimeoutSeconds,
+				MaxRetries:  defaultOpenAIMaxRetries,
+				OrgID:       "", // Empty default for optional org ID
+				Chunking:    chunking(defaultOpenAIChunkTokens),
+			},
+			Gemini: GeminiConfig{
+				BaseURL:         defaultGeminiBaseURL,
+				Model:           defaultGeminiModel,
+				Temperature:     defaultGeminiTemperature,
+				MaxOutputTokens: defaultGeminiMaxOutputTokens,
+				TimeoutSecs:     defaultGeminiTimeoutSeconds,
+				MaxRetries:      defaultGeminiMaxRetries,
+				Chunking:        chunking(defaultGeminiChunkTokens),
+			},
+		},
+		Endpoints: EndpointConfig{},
+		Profiles:  ProfileConfig{},
+		Summaries: SummaryConfig{
+			"short": {SystemPrompt: constants.ShortSummaryPrompt},
+			"long":  {SystemPrompt: constants.LongSummaryPrompt},
+		},
+		Transcripts: TranscriptConfig{SystemPrompt: constants.TranscriptPrompt},
+		Queries:     QueryConfig{SystemPrompt: constants.QueryPrompt},
+		Chat:        ChatConfig{SystemPrompt: constants.ChatPrompt},
+		Cache: CacheConfig{
+			TranscriptTTLHours: defaultTranscriptCacheTTLHours,
+			Responses:          false,
+		},
+		Watch: WatchConfig{
+			FeedBaseURL:     defaultWatchFeedBaseURL,
+			Channels:        []string{},
+			IntervalMinutes: defaultWatchIntervalMinutes,
+			OutputDir:       defaultWatchOutputDir,
+		},
+		Secrets: SecretsConfig{
+			Order: []string{SecretStoreEnv, SecretStoreKeyring, SecretStoreFile},
+			Store: SecretStoreKeyring,
+		},
 	}
-	return &config, nil
 }
 
-func setDefaults() {
-	// Global defaults
-	viper.SetDefault("provider", defaultProvider)
-
-	// Provider-specific defaults
-	viper.SetDefault("providers.lmstudio.base_url", defaultLMStudioURL)
-	viper.SetDefault("providers.lmstudio.model", defaultLMStudioModel)
-
-	viper.SetDefault("providers.ollama.base_url", defaultOllamaURL)
-	viper.SetDefault("providers.ollama.model", defaultOllamaModel)
-
-	viper.SetDefault("providers.claude.model", defaultClaudeModel)
-	viper.SetDefault("providers.claude.temperature", defaultClaudeTemperature)
-	viper.SetDefault("providers.claude.max_tokens", defaultClaudeMaxTokens)
-	viper.SetDefault("providers.claude.timeout_seconds", defaultClaudeTimeoutSeconds)
-	viper.SetDefault("providers.claude.max_retries", defaultClaudeMaxRetries)
-
-	viper.SetDefault("providers.openai.model", defaultOpenAIModel)
-	viper.SetDefault("providers.openai.temperature", defaultOpenAITemperature)
-	viper.SetDefault("providers.openai.max_tokens", defaultOpenAIMaxTokens)
-	viper.SetDefault("providers.openai.timeout_seconds", defaultOpenAITimeoutSeconds)
-	viper.SetDefault("providers.openai.max_retries", defaultOpenAIMaxRetries)
-	viper.SetDefault("providers.openai.organization_id", "") // Empty default for optional org ID
-
-	// Set summary template defaults
-	viper.SetDefault("summaries.short.system_prompt", constants.ShortSummaryPrompt)
-	viper.SetDefault("summaries.long.system_prompt", constants.LongSummaryPrompt)
-	viper.SetDefault("transcripts.system_prompt", constants.TranscriptPrompt)
-	viper.SetDefault("queries.system_prompt", constants.QueryPrompt)
+// SummaryStyle returns the named summary style
+func (c *Config) SummaryStyle(name string) (SummaryStyle, error) {
+	style, ok := c.Summaries[name]
+	if !ok || style.SystemPrompt == "" {
+		return SummaryStyle{}, fmt.Errorf("unknown summary style: %s (available: %s)", name, strings.Join(c.SummaryStyleNames(), ", "))
+	}
+	return style, nil
 }
 
-func bindEnvVars() {
-	viper.BindEnv("provider", "YTS_PROVIDER")
-
-	// LM Studio env vars
-	viper.BindEnv("providers.lmstudio.base_url", "YTS_LMSTUDIO_URL")
-	viper.BindEnv("providers.lmstudio.model", "YTS_LMSTUDIO_MODEL")
-
-	// Ollama env vars
-	viper.BindEnv("providers.ollama.base_url", "YTS_OLLAMA_URL")
-	viper.BindEnv("providers.ollama.model", "YTS_OLLAMA_MODEL")
-
-	// Claude env vars
-	viper.BindEnv("providers.claude.model", "YTS_CLAUDE_MODEL")
-	viper.BindEnv("providers.claude.temperature", "YTS_CLAUDE_TEMPERATURE")
-	viper.BindEnv("providers.claude.max_tokens", "YTS_CLAUDE_MAX_TOKENS")
-	viper.BindEnv("providers.claude.timeout", "YTS_CLAUDE_TIMEOUT")
-
-	// OpenAI env vars
-	viper.BindEnv("providers.openai.model", "YTS_OPENAI_MODEL")
-	viper.BindEnv("providers.openai.temperature", "YTS_OPENAI_TEMPERATURE")
-	viper.BindEnv("providers.openai.max_tokens", "YTS_OPENAI_MAX_TOKENS")
-	viper.BindEnv("providers.openai.timeout", "YTS_OPENAI_TIMEOUT")
-	viper.BindEnv("providers.openai.organization_id", "YTS_OPENAI_ORG_ID")
+// SummaryStyleNames returns the names of the configured summary styles, sorted
+func (c *Config) SummaryStyleNames() []string {
+	names := make([]string, 0, len(c.Summaries))
+	for name, style := range c.Summaries {
+		if style.SystemPrompt != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
-// GetSystemPrompt returns the appropriate system prompt based on summary type
-func GetSystemPrompt(summaryType string) string {
-	cfg, err := GetConfig()
-	if err != nil {
-		return ""
+// WithSummaryStyle returns a copy of the config with the style's provider
+// and model overrides applied. The temperature override is left to the caller,
+// which applies it to each request on top of the provider's configured
+// temperature.
+func (c *Config) WithSummaryStyle(style SummaryStyle) *Config {
+	copied := *c
+	if style.Provider != "" {
+		copied.Provider = style.Provider
+	}
+	if style.Model != "" {
+		switch copied.Provider {
+		case "lmstudio":
+			copied.Providers.LMStudio.Model = style.Model
+		case "ollama":
+			copied.Providers.Ollama.Model = style.Model
+		case "claude":
+			copied.Providers.Claude.Model = style.Model
+		case "openai":
+			copied.Providers.OpenAI.Model = style.Model
+		case "gemini":
+			copied.Providers.Gemini.Model = style.Model
+		default:
+			if endpoint, ok := copied.Endpoints[copied.Provider]; ok {
+				// Copy the map, so the original config keeps its model
+				copied.Endpoints = EndpointConfig{}
+				for name, e := range c.Endpoints {
+					copied.Endpoints[name] = e
+				}
+				endpoint.Model = style.Model
+				copied.Endpoints[copied.Provider] = endpoint
+			}
+		}
 	}
+	return &copied
+}
 
-	switch summaryType {
-	case "long":
-		return cfg.Summaries.Long.SystemPrompt
-	default:
-		return cfg.Summaries.Short.SystemPrompt
+// ActiveModel returns the model of the currently selected provider
+func (c *Config) ActiveModel() string {
+	switch c.Provider {
+	case "lmstudio":
+		return c.Providers.LMStudio.Model
+	case "ollama":
+		return c.Providers.Ollama.Model
+	case "claude":
+		return c.Providers.Claude.Model
+	case "openai":
+		return c.Providers.OpenAI.Model
+	case "gemini":
+		return c.Providers.Gemini.Model
 	}
+	if endpoint, ok := c.Endpoint(c.Provider); ok {
+		return endpoint.Model
+	}
+	return ""
 }
 
-// GetActiveProvider returns the config for the currently selected provider
-func (c *Config) GetActiveProvider() (baseURL string, model string, err error) {
+// GetChunking returns the chunking settings for the currently selected provider
+func (c *Config) GetChunking() ChunkingConfig {
 	switch c.Provider {
 	case "lmstudio":
-		return c.Providers.LMStudio.BaseURL, c.Providers.LMStudio.Model, nil
+		return c.Providers.LMStudio.Chunking
 	case "ollama":
-		return c.Providers.Ollama.BaseURL, c.Providers.Ollama.Model, nil
-	default:
-		return "", "", fmt.Errorf("unsupported provider: %s", c.Provider)
+		return c.Providers.Ollama.Chunking
+	case "claude":
+		return c.Providers.Claude.Chunking
+	case "openai":
+		return c.Providers.OpenAI.Chunking
+	case "gemini":
+		return c.Providers.Gemini.Chunking
+	}
+	if endpoint, ok := c.Endpoint(c.Provider); ok {
+		return endpoint.Chunking
 	}
+	return ChunkingConfig{}
 }
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/conormkelly/yts-cli/internal/constants"
+)
+
+// EndpointTypeOpenAICompatible is an endpoint that speaks the OpenAI Chat
+// Completions API, such as vLLM, llama.cpp server, Groq, Together,
+// OpenRouter or LocalAI
+const EndpointTypeOpenAICompatible = "openai-compatible"
+
+const defaultEndpointTimeoutSeconds = 120
+
+// BuiltinProviders lists the providers yts has its own settings for. Endpoints
+// add more under names of the user's choosing.
+var BuiltinProviders = []string{"lmstudio", "ollama", "claude", "openai", "gemini"}
+
+// EndpointNamePattern matches valid endpoint names
+var EndpointNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// EndpointConfig maps endpoint names to endpoints
+type EndpointConfig map[string]Endpoint
+
+// Endpoint is a user-defined provider. It is selected by name like the
+// built-in providers, e.g. with --provider groq.
+type Endpoint struct {
+	Type        string            `mapstructure:"type,omitempty"` // Defaults to openai-compatible
+	BaseURL     string            `mapstructure:"base_url"`       // API root; /chat/completions is appended
+	Model       string            `mapstructure:"model"`
+	APIKeyRef   string            `mapstructure:"api_key_ref,omitempty"` // Name of the API key in the secret stores; empty sends none
+	Headers     map[string]string `mapstructure:"headers,omitempty"`
+	Temperature *float64          `mapstructure:"temperature,omitempty"` // Unset uses the server's default
+	TopP        *float64          `mapstructure:"top_p,omitempty"`
+	MaxTokens   int               `mapstructure:"max_tokens,omitempty"`
+	TimeoutSecs int               `mapstructure:"timeout_seconds,omitempty"`
+	MaxRetries  int               `mapstructure:"max_retries,omitempty"`
+	Chunking    ChunkingConfig    `mapstructure:"chunking,omitempty"` // Chunking is off unless chunk_tokens is set
+}
+
+// IsBuiltinProvider reports whether name is one of BuiltinProviders
+func IsBuiltinProvider(name string) bool {
+	for _, provider := range BuiltinProviders {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderNames returns the built-in providers followed by the configured
+// endpoints, sorted
+func (c *Config) ProviderNames() []string {
+	names := append([]string{}, BuiltinProviders...)
+	var endpoints []string
+	for name := range c.Endpoints {
+		if !IsBuiltinProvider(name) {
+			endpoints = append(endpoints, name)
+		}
+	}
+	sort.Strings(endpoints)
+	return append(names, endpoints...)
+}
+
+// IsProvider reports whether name is a built-in provider or a configured endpoint
+func (c *Config) IsProvider(name string) bool {
+	_, ok := c.Endpoint(name)
+	return ok || IsBuiltinProvider(name)
+}
+
+// Endpoint returns the named endpoint with defaults filled in. Endpoints
+// can't replace built-in providers.
+func (c *Config) Endpoint(name string) (Endpoint, bool) {
+	endpoint, ok := c.Endpoints[name]
+	if !ok || IsBuiltinProvider(name) {
+		return Endpoint{}, false
+	}
+
+	if endpoint.Type == "" {
+		endpoint.Type = EndpointTypeOpenAICompatible
+	}
+	if endpoint.TimeoutSecs == 0 {
+		endpoint.TimeoutSecs = defaultEndpointTimeoutSeconds
+	}
+	if endpoint.Chunking.ChunkTokens > 0 {
+		if endpoint.Chunking.OverlapTokens == 0 {
+			endpoint.Chunking.OverlapTokens = defaultChunkOverlapTokens
+		}
+		if endpoint.Chunking.ReducePrompt == "" {
+			endpoint.Chunking.ReducePrompt = constants.ReducePrompt
+		}
+	}
+	return endpoint, true
+}
+
+// APIKeyRefs returns the API key names referenced by endpoints, sorted and
+// without duplicates
+func (c *Config) APIKeyRefs() []string {
+	seen := map[string]bool{}
+	var refs []string
+	for name, endpoint := range c.Endpoints {
+		if endpoint.APIKeyRef != "" && !seen[endpoint.APIKeyRef] && !IsBuiltinProvider(name) {
+			seen[endpoint.APIKeyRef] = true
+			refs = append(refs, endpoint.APIKeyRef)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// providerKey returns the config key holding a provider's settings
+func providerKey(name string) string {
+	if IsBuiltinProvider(name) {
+		return "providers." + name
+	}
+	return "endpoints." + name
+}
+
+// CreateEndpoint adds an endpoint to the config file. It fails if the
+// endpoint already exists.
+func (l *Loader) CreateEndpoint(name string, endpoint Endpoint) error {
+	if !EndpointNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid endpoint name: %s (use lowercase letters, digits, - and _)", name)
+	}
+	if IsBuiltinProvider(name) {
+		return fmt.Errorf("%s is a built-in provider; choose another name", name)
+	}
+
+	return l.update(func(raw map[string]interface{}) error {
+		if getPath(raw, "endpoints."+name) != nil {
+			return fmt.Errorf("endpoint already exists: %s", name)
+		}
+
+		settings := map[string]interface{}{}
+		for key, value := range structToMap(endpoint) {
+			setPath(settings, key, value)
+		}
+		setPath(raw, "endpoints."+name, settings)
+		return nil
+	})
+}
+
+// DeleteEndpoint removes an endpoint from the config file. The active
+// provider can't be deleted.
+func (l *Loader) DeleteEndpoint(name string) error {
+	return l.update(func(raw map[string]interface{}) error {
+		endpoints, _ := raw["endpoints"].(map[string]interface{})
+		if _, ok := endpoints[name]; !ok {
+			return fmt.Errorf("unknown endpoint: %s", name)
+		}
+		if raw["provider"] == name {
+			return fmt.Errorf("endpoint %s is the current provider; switch with 'yts config set provider <name>' first", name)
+		}
+		delete(endpoints, name)
+		return nil
+	})
+}
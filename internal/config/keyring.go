@@ -2,53 +2,154 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-
-	"github.com/zalando/go-keyring"
+	"path/filepath"
+	"strings"
 )
 
 const (
 	KeyringService = "yts-cli"
 )
 
-// APIKeyManager handles secure storage and retrieval of API keys
+// APIKeyManager looks up API keys in the secret stores listed in
+// secrets.order, and saves them in the store named by secrets.store
 type APIKeyManager struct {
-	service string
+	stores map[string]SecretStore
+	order  []string
+	store  string
+	dir    string // Config directory, for the verification times
 }
 
-// NewAPIKeyManager creates a new instance of APIKeyManager
-func NewAPIKeyManager() *APIKeyManager {
-	return &APIKeyManager{
-		service: KeyringService,
+// NewAPIKeyManager creates a new instance of APIKeyManager. A config from
+// WithSecretStores uses only its own stores, in the order given.
+func NewAPIKeyManager(cfg *Config) *APIKeyManager {
+	m := &APIKeyManager{
+		stores: map[string]SecretStore{},
+		order:  cfg.Secrets.Order,
+		store:  cfg.Secrets.Store,
+	}
+	if dir, err := cfg.Dir(); err == nil {
+		m.dir = dir
+	}
+
+	if len(cfg.secretStores) > 0 {
+		m.order = nil
+		for _, store := range cfg.secretStores {
+			if _, ok := m.stores[store.Name()]; !ok {
+				m.order = append(m.order, store.Name())
+				m.stores[store.Name()] = store
+			}
+		}
+		m.store = m.order[0]
+		return m
+	}
+
+	stores := []SecretStore{NewEnvStore(), NewKeyringStore()}
+	if m.dir != "" {
+		stores = append(stores, NewFileStore(filepath.Join(m.dir, secretsFileName)))
 	}
+	for _, store := range stores {
+		m.stores[store.Name()] = store
+	}
+	return m
+}
+
+// Order returns the names of the secret stores searched, in order
+func (m *APIKeyManager) Order() []string {
+	return m.order
 }
 
-// SetAPIKey stores an API key in the system keyring
-func (m *APIKeyManager) SetAPIKey(provider, apiKey string) error {
-	if err := keyring.Set(m.service, provider, apiKey); err != nil {
-		return fmt.Errorf("failed to store API key for %s: %w", provider, err)
+// SetAPIKey stores an API key in the configured secret store, returning
+// the store's name
+func (m *APIKeyManager) SetAPIKey(provider, apiKey string) (string, error) {
+	store, ok := m.stores[m.store]
+	if !ok {
+		return "", fmt.Errorf("unknown secret store: %s", m.store)
 	}
-	return nil
+	if err := store.Set(provider, apiKey); err != nil {
+		return "", fmt.Errorf("failed to store API key for %s in %s: %w", provider, store.Name(), err)
+	}
+	return store.Name(), nil
 }
 
 func (m *APIKeyManager) HasAPIKey(provider string) bool {
-	_, err := keyring.Get(m.service, provider)
+	_, _, err := m.LookupAPIKey(provider)
 	return err == nil
 }
 
-// GetAPIKey retrieves an API key from the system keyring
+// GetAPIKey retrieves an API key from the first secret store that has one
 func (m *APIKeyManager) GetAPIKey(provider string) (string, error) {
-	apiKey, err := keyring.Get(m.service, provider)
-	if err != nil {
-		return "", fmt.Errorf("failed to retrieve API key for %s: %w", provider, err)
+	apiKey, _, err := m.LookupAPIKey(provider)
+	return apiKey, err
+}
+
+// LookupAPIKey returns a provider's API key and the name of the secret
+// store that supplied it. Stores that fail, such as a keyring without a
+// Secret Service, are skipped and reported if no store has the key.
+func (m *APIKeyManager) LookupAPIKey(provider string) (apiKey string, store string, err error) {
+	notFound := &ErrAPIKeyNotFound{Provider: provider, Stores: m.order}
+	for _, name := range m.order {
+		s, ok := m.stores[name]
+		if !ok {
+			notFound.Problems = append(notFound.Problems, fmt.Sprintf("%s: unknown secret store", name))
+			continue
+		}
+
+		apiKey, err := s.Get(provider)
+		if err == nil {
+			return apiKey, name, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			notFound.Problems = append(notFound.Problems, fmt.Sprintf("%s: %v", name, err))
+		}
 	}
-	return apiKey, nil
+	return "", "", notFound
 }
 
-// DeleteAPIKey removes an API key from the system keyring
+// DeleteAPIKey removes an API key from every writable secret store that has
+// it. Stores that fail are only reported if the key wasn't deleted anywhere.
 func (m *APIKeyManager) DeleteAPIKey(provider string) error {
-	if err := keyring.Delete(m.service, provider); err != nil {
-		return fmt.Errorf("failed to delete API key for %s: %w", provider, err)
+	var problems []string
+	deleted := false
+	for _, name := range []string{SecretStoreKeyring, SecretStoreFile} {
+		store, ok := m.stores[name]
+		if !ok {
+			continue
+		}
+		err := store.Delete(provider)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		deleted = true
+	}
+
+	if !deleted {
+		msg := fmt.Sprintf("no stored API key for %s", provider)
+		if len(problems) > 0 {
+			msg += " (" + strings.Join(problems, "; ") + ")"
+		}
+		return errors.New(msg)
 	}
 	return nil
 }
+
+// ErrAPIKeyNotFound is returned when no secret store has a provider's key
+type ErrAPIKeyNotFound struct {
+	Provider string
+	Stores   []string // Stores searched
+	Problems []string // Stores that failed, with their errors
+}
+
+func (e *ErrAPIKeyNotFound) Error() string {
+	msg := fmt.Sprintf("no API key for %s in %s; set one with 'yts apikey set %s' or %s",
+		e.Provider, strings.Join(e.Stores, ", "), e.Provider, APIKeyEnvVar(e.Provider))
+	if len(e.Problems) > 0 {
+		msg += " (" + strings.Join(e.Problems, "; ") + ")"
+	}
+	return msg
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// envBindings maps config keys to the environment variables that override them
+var envBindings = []struct {
+	key string
+	env string
+}{
+	{"provider", "YTS_PROVIDER"},
+
+	// LM Studio
+	{"providers.lmstudio.base_url", "YTS_LMSTUDIO_URL"},
+	{"providers.lmstudio.model", "YTS_LMSTUDIO_MODEL"},
+
+	// Ollama
+	{"providers.ollama.base_url", "YTS_OLLAMA_URL"},
+	{"providers.ollama.model", "YTS_OLLAMA_MODEL"},
+
+	// Claude
+	{"providers.claude.base_url", "YTS_CLAUDE_BASE_URL"},
+	{"providers.claude.model", "YTS_CLAUDE_MODEL"},
+	{"providers.claude.temperature", "YTS_CLAUDE_TEMPERATURE"},
+	{"providers.claude.max_tokens", "YTS_CLAUDE_MAX_TOKENS"},
+	{"providers.claude.timeout_seconds", "YTS_CLAUDE_TIMEOUT"},
+
+	// OpenAI
+	{"providers.openai.base_url", "YTS_OPENAI_BASE_URL"},
+	{"providers.openai.model", "YTS_OPENAI_MODEL"},
+	{"providers.openai.temperature", "YTS_OPENAI_TEMPERATURE"},
+	{"providers.openai.max_tokens", "YTS_OPENAI_MAX_TOKENS"},
+	{"providers.openai.timeout_seconds", "YTS_OPENAI_TIMEOUT"},
+	{"providers.openai.organization_id", "YTS_OPENAI_ORG_ID"},
+
+	// Gemini
+	{"providers.gemini.base_url", "YTS_GEMINI_BASE_URL"},
+	{"providers.gemini.model", "YTS_GEMINI_MODEL"},
+	{"providers.gemini.temperature", "YTS_GEMINI_TEMPERATURE"},
+	{"providers.gemini.max_output_tokens", "YTS_GEMINI_MAX_OUTPUT_TOKENS"},
+	{"providers.gemini.timeout_seconds", "YTS_GEMINI_TIMEOUT"},
+
+	// Watch
+	{"watch.feed_base_url", "YTS_WATCH_FEED_BASE_URL"},
+
+	// Secrets
+	{"secrets.order", "YTS_SECRETS_ORDER"},
+	{"secrets.store", "YTS_SECRETS_STORE"},
+}
+
+// envHeaderBindings maps header settings to environment variables holding
+// comma-separated Name=Value pairs
+var envHeaderBindings = []struct {
+	key string
+	env string
+}{
+	{"providers.claude.headers", "YTS_CLAUDE_HEADERS"},
+	{"providers.openai.headers", "YTS_OPENAI_HEADERS"},
+}
+
+// Loader builds a Config from the defaults, the config file, environment
+// variables and command-line flags, in increasing order of precedence.
+// Each Loader has its own viper instance, so loaders don't share state.
+type Loader struct {
+	path      string
+	lookupEnv func(key string) (string, bool)
+	flags     map[string]*pflag.Flag
+}
+
+// LoaderOption configures a Loader
+type LoaderOption func(*Loader)
+
+// WithPath reads the config from path instead of DefaultPath
+func WithPath(path string) LoaderOption {
+	return func(l *Loader) {
+		l.path = path
+	}
+}
+
+// WithEnv reads environment variables with lookup instead of os.LookupEnv.
+// Pass a lookup that always fails to ignore the environment.
+func WithEnv(lookup func(key string) (string, bool)) LoaderOption {
+	return func(l *Loader) {
+		l.lookupEnv = lookup
+	}
+}
+
+// WithFlag overrides key with flag when the flag was set on the command line
+func WithFlag(key string, flag *pflag.Flag) LoaderOption {
+	return func(l *Loader) {
+		if flag != nil {
+			l.flags[key] = flag
+		}
+	}
+}
+
+// NewLoader creates a loader for the config file, the environment and any
+// flags given with WithFlag
+func NewLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{
+		lookupEnv: os.LookupEnv,
+		flags:     map[string]*pflag.Flag{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// DefaultPath returns the config file location in the user's config directory
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, configDirName, configFileName), nil
+}
+
+// Dir returns the directory of the config file, where yts keeps its other
+// files such as the encrypted API keys
+func (c *Config) Dir() (string, error) {
+	if c.dir != "" {
+		return c.dir, nil
+	}
+	path, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// Path returns the config file the loader reads
+func (l *Loader) Path() (string, error) {
+	if l.path != "" {
+		return l.path, nil
+	}
+	return DefaultPath()
+}
+
+// Load returns a new Config. The config file is created with the defaults
+// if it doesn't exist, and migrated to the current version if it is older.
+func (l *Loader) Load() (*Config, error) {
+	cfg, _, err := l.LoadWithSources()
+	return cfg, err
+}
+
+// LoadWithSources is Load, also reporting where each setting came from.
+// The active profile, chosen by flag, YTS_PROFILE or the config file in
+// that order, is applied over the config file and under the environment.
+func (l *Loader) LoadWithSources() (*Config, Sources, error) {
+	v, err := l.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// New config files are written with every default, so only count
+	// settings that were changed from them
+	sources := Sources{}
+	defaults := structToMap(Default())
+	for _, key := range v.AllKeys() {
+		if v.InConfig(key) && fmt.Sprint(v.Get(key)) != fmt.Sprint(defaults[key]) {
+			sources[key] = "config file"
+		}
+	}
+
+	profile, source := v.GetString("profile"), "config file"
+	if value, ok := l.lookupEnv("YTS_PROFILE"); ok && value != "" {
+		profile, source = value, "env YTS_PROFILE"
+	}
+	if flag, ok := l.flags["profile"]; ok && flag.Changed {
+		profile, source = flag.Value.String(), "flag --profile"
+	}
+	if profile != "" {
+		v.Set("profile", profile)
+		sources["profile"] = source
+		if err := applyProfile(v, profile, sources); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, binding := range envBindings {
+		if value, ok := l.lookupEnv(binding.env); ok && value != "" {
+			v.Set(binding.key, value)
+			sources[binding.key] = "env " + binding.env
+		}
+	}
+	for _, binding := range envHeaderBindings {
+		if value, ok := l.lookupEnv(binding.env); ok && value != "" {
+			headers, err := ParseHeaders(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", binding.env, err)
+			}
+			v.Set(binding.key, headers)
+			sources[binding.key] = "env " + binding.env
+		}
+	}
+	for key, flag := range l.flags {
+		if flag.Changed && key != "profile" {
+			v.Set(key, flag.Value.String())
+			sources[key] = "flag --" + flag.Name
+		}
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if path, err := l.Path(); err == nil {
+		cfg.dir = filepath.Dir(path)
+	}
+	return &cfg, sources, nil
+}
+
+// Set stores a value in the config file. Environment variables and flags
+// are not written.
+func (l *Loader) Set(key string, value interface{}) error {
+	v, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	v.Set(key, value)
+	if err := v.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
+
+// read returns a viper instance holding the defaults and the config file,
+// migrating or creating the file first if needed
+func (l *Loader) read() (*viper.Viper, error) {
+	path, err := l.Path()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Bring an existing config file up to date before reading it
+	migration, err := l.Migrate(false)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Needed() {
+		fmt.Fprintf(os.Stderr, "Configuration updated to version %s (backup saved to %s)\n", migration.To, migration.Backup)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("json")
+	setDefaults(v)
+
+	if err := v.ReadInConfig(); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+
+		// Config file doesn't exist, create it with defaults
+		v.Set("version", currentConfigVersion)
+		if err := v.WriteConfigAs(path); err != nil {
+			return nil, fmt.Errorf("failed to write default config: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// ParseHeaders parses comma-separated Name=Value pairs, as used by the
+// YTS_<PROVIDER>_HEADERS environment variables
+func ParseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, headerValue, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected Name=Value, got %q", pair)
+		}
+		headers[name] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// setDefaults registers every value from Default with v
+func setDefaults(v *viper.Viper) {
+	for key, value := range structToMap(Default()) {
+		// The version is only ever written when a config file is created or migrated
+		if key == "version" {
+			continue
+		}
+		v.SetDefault(key, value)
+	}
+}
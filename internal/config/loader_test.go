@@ -0,0 +1,386 @@
+package config
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// fakeEnv returns a lookup over vars, for WithEnv
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+// writeConfig writes a config file into a new temporary directory and
+// returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestLoadDefaultsWhenFileMissing(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "")
+	cfg, sources, err := NewLoader(WithPath(path), WithEnv(fakeEnv(nil))).LoadWithSources()
+	if err != nil {
+		t.Fatalf("LoadWithSources() error = %v", err)
+	}
+
+	defaults := Default()
+	if cfg.Provider != defaults.Provider {
+		t.Errorf("Provider = %q, want %q", cfg.Provider, defaults.Provider)
+	}
+	if !reflect.DeepEqual(cfg.Providers.Claude, defaults.Providers.Claude) {
+		t.Errorf("Providers.Claude = %+v, want %+v", cfg.Providers.Claude, defaults.Providers.Claude)
+	}
+	if !reflect.DeepEqual(cfg.Secrets, defaults.Secrets) {
+		t.Errorf("Secrets = %+v, want %+v", cfg.Secrets, defaults.Secrets)
+	}
+	if !reflect.DeepEqual(cfg.Summaries, defaults.Summaries) {
+		t.Errorf("Summaries = %+v, want %+v", cfg.Summaries, defaults.Summaries)
+	}
+	if got := sources.Of("providers.claude.model"); got != "default" {
+		t.Errorf("Sources.Of(providers.claude.model) = %q, want default", got)
+	}
+
+	// The file is created with the current version
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("config file not created: %v", err)
+	}
+	if cfg.Version != currentConfigVersion {
+		t.Errorf("Version = %q, want %q", cfg.Version, currentConfigVersion)
+	}
+}
+
+func TestLoadFileOverridesDefaults(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `{
+  "version": "1.3.0",
+  "provider": "ollama",
+  "providers": {"ollama": {"model": "mistral"}}
+}`)
+	cfg, sources, err := NewLoader(WithPath(path), WithEnv(fakeEnv(nil))).LoadWithSources()
+	if err != nil {
+		t.Fatalf("LoadWithSources() error = %v", err)
+	}
+
+	if cfg.Provider != "ollama" {
+		t.Errorf("Provider = %q, want ollama", cfg.Provider)
+	}
+	if cfg.Providers.Ollama.Model != "mistral" {
+		t.Errorf("Ollama model = %q, want mistral", cfg.Providers.Ollama.Model)
+	}
+	// Settings the file doesn't mention keep their defaults
+	if cfg.Providers.Ollama.BaseURL != defaultOllamaURL {
+		t.Errorf("Ollama base URL = %q, want %q", cfg.Providers.Ollama.BaseURL, defaultOllamaURL)
+	}
+
+	for key, want := range map[string]string{
+		"provider":                  "config file",
+		"providers.ollama.model":    "config file",
+		"providers.ollama.base_url": "default",
+	} {
+		if got := sources.Of(key); got != want {
+			t.Errorf("Sources.Of(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `{
+  "version": "1.3.0",
+  "providers": {"claude": {"model": "from-file"}},
+  "secrets": {"order": ["keyring"]}
+}`)
+	env := fakeEnv(map[string]string{
+		"YTS_CLAUDE_MODEL":   "from-env",
+		"YTS_SECRETS_ORDER":  "env,file",
+		"YTS_CLAUDE_HEADERS": "X-Gateway-Key=abc, X-Team=research",
+		"YTS_OPENAI_MODEL":   "", // Empty values are ignored
+	})
+	cfg, sources, err := NewLoader(WithPath(path), WithEnv(env)).LoadWithSources()
+	if err != nil {
+		t.Fatalf("LoadWithSources() error = %v", err)
+	}
+
+	if cfg.Providers.Claude.Model != "from-env" {
+		t.Errorf("Claude model = %q, want from-env", cfg.Providers.Claude.Model)
+	}
+	if want := []string{"env", "file"}; !reflect.DeepEqual(cfg.Secrets.Order, want) {
+		t.Errorf("Secrets.Order = %#v, want %#v", cfg.Secrets.Order, want)
+	}
+	if len(cfg.Providers.Claude.Headers) != 2 {
+		t.Errorf("Claude headers = %v, want 2 headers", cfg.Providers.Claude.Headers)
+	}
+	for name, want := range map[string]string{"x-gateway-key": "abc", "x-team": "research"} {
+		if got := headerValue(cfg.Providers.Claude.Headers, name); got != want {
+			t.Errorf("Claude header %s = %q, want %q", name, got, want)
+		}
+	}
+	if cfg.Providers.OpenAI.Model != defaultOpenAIModel {
+		t.Errorf("OpenAI model = %q, want the default %q", cfg.Providers.OpenAI.Model, defaultOpenAIModel)
+	}
+
+	for key, want := range map[string]string{
+		"providers.claude.model":   "env YTS_CLAUDE_MODEL",
+		"secrets.order":            "env YTS_SECRETS_ORDER",
+		"providers.claude.headers": "env YTS_CLAUDE_HEADERS",
+		"providers.openai.model":   "default",
+	} {
+		if got := sources.Of(key); got != want {
+			t.Errorf("Sources.Of(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadInvalidHeaderEnv(t *testing.T) {
+	t.Parallel()
+
+	env := fakeEnv(map[string]string{"YTS_OPENAI_HEADERS": "no-equals-sign"})
+	_, err := NewLoader(WithPath(writeConfig(t, "")), WithEnv(env)).Load()
+	if err == nil {
+		t.Fatal("Load() succeeded, want an error for a malformed YTS_OPENAI_HEADERS")
+	}
+}
+
+func TestLoadFlagOverridesEnv(t *testing.T) {
+	t.Parallel()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("provider", "", "")
+	flags.String("model", "", "")
+	if err := flags.Set("provider", "ollama"); err != nil {
+		t.Fatal(err)
+	}
+
+	env := fakeEnv(map[string]string{"YTS_PROVIDER": "claude", "YTS_OLLAMA_MODEL": "from-env"})
+	cfg, sources, err := NewLoader(
+		WithPath(writeConfig(t, "")),
+		WithEnv(env),
+		WithFlag("provider", flags.Lookup("provider")),
+		WithFlag("providers.ollama.model", flags.Lookup("model")), // Not set, so env wins
+	).LoadWithSources()
+	if err != nil {
+		t.Fatalf("LoadWithSources() error = %v", err)
+	}
+
+	if cfg.Provider != "ollama" {
+		t.Errorf("Provider = %q, want ollama", cfg.Provider)
+	}
+	if got := sources.Of("provider"); got != "flag --provider" {
+		t.Errorf("Sources.Of(provider) = %q, want flag --provider", got)
+	}
+	if cfg.Providers.Ollama.Model != "from-env" {
+		t.Errorf("Ollama model = %q, want from-env", cfg.Providers.Ollama.Model)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	t.Parallel()
+
+	const file = `{
+  "version": "1.3.0",
+  "provider": "ollama",
+  "profile": "cloud",
+  "profiles": {
+    "cloud": {
+      "provider": "claude",
+      "model": "profile-model",
+      "temperature": 0.9,
+      "summaries": {"short": {"system_prompt": "Profile prompt for {{.Title}}"}}
+    },
+    "local": {"model": "llama3.1"}
+  }
+}`
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		flag        string
+		wantErr     bool
+		wantProfile string
+		wantSource  string
+		check       func(t *testing.T, cfg *Config, sources Sources)
+	}{
+		{
+			name:        "active profile from the config file",
+			wantProfile: "cloud",
+			wantSource:  "config file",
+			check: func(t *testing.T, cfg *Config, sources Sources) {
+				if cfg.Provider != "claude" {
+					t.Errorf("Provider = %q, want claude", cfg.Provider)
+				}
+				if cfg.Providers.Claude.Model != "profile-model" {
+					t.Errorf("Claude model = %q, want profile-model", cfg.Providers.Claude.Model)
+				}
+				if cfg.Providers.Claude.Temperature != 0.9 {
+					t.Errorf("Claude temperature = %v, want 0.9", cfg.Providers.Claude.Temperature)
+				}
+				if got := cfg.Summaries["short"].SystemPrompt; got != "Profile prompt for {{.Title}}" {
+					t.Errorf("short prompt = %q, want the profile's", got)
+				}
+				// Other styles keep the defaults
+				if got := cfg.Summaries["long"].SystemPrompt; got != Default().Summaries["long"].SystemPrompt {
+					t.Errorf("long prompt = %q, want the default", got)
+				}
+				for key, want := range map[string]string{
+					"provider":                      "profile cloud",
+					"providers.claude.model":        "profile cloud",
+					"summaries.short.system_prompt": "profile cloud",
+					"providers.claude.max_tokens":   "default",
+					"summaries.long.system_prompt":  "default",
+				} {
+					if got := sources.Of(key); got != want {
+						t.Errorf("Sources.Of(%s) = %q, want %q", key, got, want)
+					}
+				}
+			},
+		},
+		{
+			name:        "env overrides the profile",
+			env:         map[string]string{"YTS_CLAUDE_MODEL": "env-model"},
+			wantProfile: "cloud",
+			wantSource:  "config file",
+			check: func(t *testing.T, cfg *Config, sources Sources) {
+				if cfg.Providers.Claude.Model != "env-model" {
+					t.Errorf("Claude model = %q, want env-model", cfg.Providers.Claude.Model)
+				}
+				if got := sources.Of("providers.claude.model"); got != "env YTS_CLAUDE_MODEL" {
+					t.Errorf("Sources.Of(providers.claude.model) = %q", got)
+				}
+			},
+		},
+		{
+			name:        "YTS_PROFILE selects another profile",
+			env:         map[string]string{"YTS_PROFILE": "local"},
+			wantProfile: "local",
+			wantSource:  "env YTS_PROFILE",
+			check: func(t *testing.T, cfg *Config, sources Sources) {
+				// The profile has no provider, so its model applies to the configured one
+				if cfg.Provider != "ollama" {
+					t.Errorf("Provider = %q, want ollama", cfg.Provider)
+				}
+				if cfg.Providers.Ollama.Model != "llama3.1" {
+					t.Errorf("Ollama model = %q, want llama3.1", cfg.Providers.Ollama.Model)
+				}
+				if got := sources.Of("providers.ollama.model"); got != "profile local" {
+					t.Errorf("Sources.Of(providers.ollama.model) = %q, want profile local", got)
+				}
+			},
+		},
+		{
+			name:        "flag overrides YTS_PROFILE",
+			env:         map[string]string{"YTS_PROFILE": "local"},
+			flag:        "cloud",
+			wantProfile: "cloud",
+			wantSource:  "flag --profile",
+		},
+		{
+			name:    "unknown profile",
+			flag:    "missing",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("profile", "", "")
+			if tt.flag != "" {
+				if err := flags.Set("profile", tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, sources, err := NewLoader(
+				WithPath(writeConfig(t, file)),
+				WithEnv(fakeEnv(tt.env)),
+				WithFlag("profile", flags.Lookup("profile")),
+			).LoadWithSources()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadWithSources() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWithSources() error = %v", err)
+			}
+
+			if cfg.Profile != tt.wantProfile {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, tt.wantProfile)
+			}
+			if got := sources.Of("profile"); got != tt.wantSource {
+				t.Errorf("Sources.Of(profile) = %q, want %q", got, tt.wantSource)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg, sources)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{value: "X-A=1", want: map[string]string{"X-A": "1"}},
+		{value: " X-A = 1 , X-B=two,", want: map[string]string{"X-A": "1", "X-B": "two"}},
+		{value: "X-Empty=", want: map[string]string{"X-Empty": ""}},
+		{value: "X-Token=a=b", want: map[string]string{"X-Token": "a=b"}},
+		{value: "", want: map[string]string{}},
+		{value: "missing", wantErr: true},
+		{value: "=value", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHeaders(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseHeaders(%q) succeeded, want an error", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHeaders(%q) error = %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHeaders(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// headerValue looks up a header regardless of case, since viper lowercases
+// map keys
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(name) {
+			return value
+		}
+	}
+	return ""
+}
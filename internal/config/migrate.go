@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/constants"
+)
+
+// migration upgrades a raw config from one version to the next. Steps work
+// on the decoded JSON rather than Config, so settings this version of yts
+// doesn't know about survive the upgrade untouched.
+type migration struct {
+	from        string
+	to          string
+	description string
+	apply       func(raw map[string]interface{})
+}
+
+// migrations lists every upgrade step. Each step's from version must be
+// unique, and following the steps from any version must end at
+// currentConfigVersion.
+var migrations = []migration{
+	{
+		from:        "1.0.0",
+		to:          "1.0.1",
+		description: "add the query prompt",
+		apply: func(raw map[string]interface{}) {
+			if prompt, _ := getPath(raw, "queries.system_prompt").(string); prompt == "" {
+				setPath(raw, "queries.system_prompt", constants.QueryPrompt)
+			}
+		},
+	},
+	{
+		// Earlier releases labelled migrated 1.0.0 configs as 1.1.0, a version
+		// that never existed, so they were re-migrated on every run
+		from:        "1.1.0",
+		to:          "1.0.1",
+		description: "fix the version of configs migrated from 1.0.0",
+		apply:       func(raw map[string]interface{}) {},
+	},
+	{
+		from:        "1.0.1",
+		to:          "1.2.0",
+		description: "move the short and long prompts into the summary styles",
+		apply: func(raw map[string]interface{}) {
+			builtIn := map[string]string{
+				"short": constants.ShortSummaryPrompt,
+				"long":  constants.LongSummaryPrompt,
+			}
+			for name, prompt := range builtIn {
+				key := "summaries." + name + ".system_prompt"
+				if existing, _ := getPath(raw, key).(string); existing == "" {
+					setPath(raw, key, prompt)
+				}
+			}
+		},
+	},
+	{
+		from:        "1.2.0",
+		to:          "1.3.0",
+		description: "convert prompt placeholders to template syntax",
+		apply: func(raw map[string]interface{}) {
+			replacer := strings.NewReplacer(
+				"{{title}}", "{{.Title}}",
+				"{{query}}", "{{.Query}}",
+				"{{transcript}}", "{{.Transcript}}",
+			)
+			replaceStrings(raw, replacer)
+		},
+	},
+}
+
+// replaceStrings applies r to every string value in raw, at any depth
+func replaceStrings(raw map[string]interface{}, r *strings.Replacer) {
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			raw[key] = r.Replace(v)
+		case map[string]interface{}:
+			replaceStrings(v, r)
+		}
+	}
+}
+
+// unversionedConfigVersion is assumed for config files without a version
+const unversionedConfigVersion = "1.0.0"
+
+// Migration describes the upgrade of a config file to the current version
+type Migration struct {
+	From   string
+	To     string
+	Steps  []string // One description per step applied
+	Before map[string]interface{}
+	After  map[string]interface{}
+	Backup string // Path of the backup, empty for a dry run
+}
+
+// Needed reports whether the config file was out of date
+func (m *Migration) Needed() bool {
+	return len(m.Steps) > 0
+}
+
+// Migrate upgrades the config file to the current version, saving a
+// timestamped backup of the original first. With dryRun, it reports the
+// changes without writing anything. A missing config file needs no migration.
+func (l *Loader) Migrate(dryRun bool) (*Migration, error) {
+	path, err := l.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Migration{From: currentConfigVersion, To: currentConfigVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(data, &before); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	// Decode twice so the steps can't modify the original
+	json.Unmarshal(data, &after)
+
+	result := &Migration{Before: before, After: after}
+	result.From, result.Steps, err = migrateRaw(after)
+	result.To = currentConfigVersion
+	if err != nil || !result.Needed() || dryRun {
+		return result, err
+	}
+
+	result.Backup, err = backupFile(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up config: %w", err)
+	}
+
+	migrated, err := json.MarshalIndent(after, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	if err := os.WriteFile(path, append(migrated, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to save migrated config: %w", err)
+	}
+	return result, nil
+}
+
+// backupFile writes data to a new timestamped file next to path, never
+// overwriting an earlier backup
+func backupFile(path string, data []byte) (string, error) {
+	base := fmt.Sprintf("%s.%s", path, time.Now().Format("20060102-150405"))
+	for i := 0; ; i++ {
+		backup := base + ".bak"
+		if i > 0 {
+			backup = fmt.Sprintf("%s-%d.bak", base, i)
+		}
+
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
+
+// migrateRaw applies migration steps to raw until it reaches the current
+// version, returning the starting version and the steps applied
+func migrateRaw(raw map[string]interface{}) (string, []string, error) {
+	from, _ := raw["version"].(string)
+	if from == "" {
+		from = unversionedConfigVersion
+	}
+
+	var steps []string
+	version := from
+	for version != currentConfigVersion {
+		step, ok := findMigration(version)
+		if !ok || len(steps) > len(migrations) {
+			return from, nil, fmt.Errorf("don't know how to migrate config version %s to %s", version, currentConfigVersion)
+		}
+		step.apply(raw)
+		version = step.to
+		steps = append(steps, fmt.Sprintf("%s → %s: %s", step.from, step.to, step.description))
+	}
+
+	if len(steps) > 0 {
+		raw["version"] = currentConfigVersion
+	}
+	return from, steps, nil
+}
+
+func findMigration(from string) (migration, bool) {
+	for _, m := range migrations {
+		if m.from == from {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+// Change is a single setting that differs between two configs
+type Change struct {
+	Key string
+	Old interface{} // nil when the setting was added
+	New interface{} // nil when the setting was removed
+}
+
+// Diff lists the settings that differ between two raw configs, sorted by key
+func Diff(before, after map[string]interface{}) []Change {
+	old, updated := flatten(before), flatten(after)
+
+	var changes []Change
+	for key, value := range old {
+		if newValue, ok := updated[key]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes = append(changes, Change{Key: key, Old: value, New: updated[key]})
+		}
+	}
+	for key, value := range updated {
+		if _, ok := old[key]; !ok {
+			changes = append(changes, Change{Key: key, New: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// flatten maps dotted keys to the leaf values of a raw config
+func flatten(raw map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range raw {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for k, v := range flatten(nested) {
+				result[key+"."+k] = v
+			}
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// getPath returns the value at a dotted key, or nil if it isn't set
+func getPath(raw map[string]interface{}, key string) interface{} {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := raw[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		raw = nested
+	}
+	return raw[parts[len(parts)-1]]
+}
+
+// setPath sets the value at a dotted key, creating parent objects as needed
+func setPath(raw map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := raw[part].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			raw[part] = nested
+		}
+		raw = nested
+	}
+	raw[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/constants"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fixture   string
+		wantFrom  string
+		wantSteps int
+		want      map[string]interface{} // Expected values at dotted keys after migrating
+	}{
+		{
+			fixture:   "unversioned.json",
+			wantFrom:  "1.0.0",
+			wantSteps: 3,
+			want: map[string]interface{}{
+				"provider":                      "ollama",
+				"providers.ollama.model":        "llama3.2",
+				"queries.system_prompt":         constants.QueryPrompt,
+				"summaries.short.system_prompt": "Summarize {{.Title}} in one paragraph.",
+				"summaries.long.system_prompt":  constants.LongSummaryPrompt,
+			},
+		},
+		{
+			fixture:   "v1.0.0.json",
+			wantFrom:  "1.0.0",
+			wantSteps: 3,
+			want: map[string]interface{}{
+				"provider":                      "claude",
+				"providers.claude.temperature":  0.3,
+				"queries.system_prompt":         constants.QueryPrompt,
+				"summaries.short.system_prompt": "Summarize {{.Title}} in one paragraph.",
+				"summaries.long.system_prompt":  "Summarize {{.Title}} in detail.",
+			},
+		},
+		{
+			fixture:   "v1.1.0.json",
+			wantFrom:  "1.1.0",
+			wantSteps: 3,
+			want: map[string]interface{}{
+				"provider":                      "lmstudio",
+				"queries.system_prompt":         "Answer {{.Query}} about {{.Title}} using {{.Transcript}}.",
+				"summaries.short.system_prompt": "Be brief about {{.Title}}.",
+				"summaries.long.system_prompt":  "Be thorough about {{.Title}}.",
+			},
+		},
+		{
+			fixture:   "v1.0.1.json",
+			wantFrom:  "1.0.1",
+			wantSteps: 2,
+			want: map[string]interface{}{
+				"provider":                      "openai",
+				"queries.system_prompt":         "Answer {{.Query}} about {{.Title}}.",
+				"summaries.short.system_prompt": "Be brief about {{.Title}}.",
+				"summaries.long.system_prompt":  constants.LongSummaryPrompt,
+			},
+		},
+		{
+			fixture:   "v1.2.0.json",
+			wantFrom:  "1.2.0",
+			wantSteps: 1,
+			want: map[string]interface{}{
+				"custom_setting":                  "kept",
+				"queries.system_prompt":           "Answer {{.Query}} about {{.Title}}.",
+				"summaries.long.system_prompt":    "Be thorough about {{.Title}}.",
+				"summaries.bullets.system_prompt": "List the points of {{.Title}}.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			original, err := os.ReadFile(filepath.Join("testdata", "migrate", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := writeConfig(t, string(original))
+			loader := NewLoader(WithPath(path), WithEnv(fakeEnv(nil)))
+
+			// A dry run reports the migration without writing anything
+			migration, err := loader.Migrate(true)
+			if err != nil {
+				t.Fatalf("Migrate(true) error = %v", err)
+			}
+			if migration.From != tt.wantFrom || migration.To != currentConfigVersion {
+				t.Errorf("Migrate(true) = %s → %s, want %s → %s", migration.From, migration.To, tt.wantFrom, currentConfigVersion)
+			}
+			if len(migration.Steps) != tt.wantSteps {
+				t.Errorf("Migrate(true) steps = %q, want %d steps", migration.Steps, tt.wantSteps)
+			}
+			if migration.Backup != "" {
+				t.Errorf("Migrate(true) backup = %q, want none", migration.Backup)
+			}
+			assertFile(t, path, original)
+			assertBackups(t, path, 0)
+
+			// A real run backs up the original and rewrites the file
+			migration, err = loader.Migrate(false)
+			if err != nil {
+				t.Fatalf("Migrate(false) error = %v", err)
+			}
+			if len(migration.Steps) != tt.wantSteps {
+				t.Errorf("Migrate(false) steps = %q, want %d steps", migration.Steps, tt.wantSteps)
+			}
+			backups := assertBackups(t, path, 1)
+			if len(backups) == 1 {
+				if backups[0] != migration.Backup {
+					t.Errorf("backup = %s, want %s", backups[0], migration.Backup)
+				}
+				assertFile(t, backups[0], original)
+			}
+
+			migrated, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var raw map[string]interface{}
+			if err := json.Unmarshal(migrated, &raw); err != nil {
+				t.Fatalf("migrated config isn't valid JSON: %v", err)
+			}
+			if raw["version"] != currentConfigVersion {
+				t.Errorf("version = %v, want %s", raw["version"], currentConfigVersion)
+			}
+			for key, want := range tt.want {
+				if got := getPath(raw, key); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", key, got, want)
+				}
+			}
+
+			// Migrating again is a no-op
+			migration, err = loader.Migrate(false)
+			if err != nil {
+				t.Fatalf("second Migrate(false) error = %v", err)
+			}
+			if migration.Needed() {
+				t.Errorf("second Migrate(false) applied %q, want no steps", migration.Steps)
+			}
+			assertFile(t, path, migrated)
+			assertBackups(t, path, 1)
+		})
+	}
+}
+
+func TestMigrateUnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `{"version": "0.9.0", "provider": "ollama"}`)
+	_, err := NewLoader(WithPath(path), WithEnv(fakeEnv(nil))).Migrate(false)
+	if err == nil {
+		t.Fatal("Migrate() succeeded, want an error")
+	}
+	if want := "don't know how to migrate config version 0.9.0"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Migrate() error = %q, want it to contain %q", err, want)
+	}
+	assertBackups(t, path, 0)
+}
+
+func TestMigrateMissingFile(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "")
+	migration, err := NewLoader(WithPath(path), WithEnv(fakeEnv(nil))).Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if migration.Needed() {
+		t.Errorf("Migrate() applied %q to a missing file", migration.Steps)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Migrate() created %s", path)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]interface{}{
+		"version":  "1.2.0",
+		"provider": "ollama",
+		"removed":  true,
+		"summaries": map[string]interface{}{
+			"short": map[string]interface{}{"system_prompt": "{{title}}"},
+			"long":  map[string]interface{}{"system_prompt": "same"},
+		},
+	}
+	after := map[string]interface{}{
+		"version":  "1.3.0",
+		"provider": "ollama",
+		"summaries": map[string]interface{}{
+			"short": map[string]interface{}{"system_prompt": "{{.Title}}"},
+			"long":  map[string]interface{}{"system_prompt": "same"},
+		},
+		"queries": map[string]interface{}{"system_prompt": "added"},
+	}
+
+	want := []Change{
+		{Key: "queries.system_prompt", New: "added"},
+		{Key: "removed", Old: true},
+		{Key: "summaries.short.system_prompt", Old: "{{title}}", New: "{{.Title}}"},
+		{Key: "version", Old: "1.2.0", New: "1.3.0"},
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %#v, want %#v", got, want)
+	}
+	if got := Diff(after, after); len(got) != 0 {
+		t.Errorf("Diff() of identical configs = %#v, want none", got)
+	}
+}
+
+// assertFile fails the test unless the file at path contains want
+func assertFile(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+	}
+}
+
+// assertBackups fails the test unless there are want backups of path, and
+// returns them
+func assertBackups(t *testing.T, path string, want int) []string {
+	t.Helper()
+	backups, err := filepath.Glob(path + ".*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != want {
+		t.Errorf("backups = %q, want %d", backups, want)
+	}
+	return backups
+}
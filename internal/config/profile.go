@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileConfig maps profile names to profiles
+type ProfileConfig map[string]Profile
+
+// Profile is a named set of overrides, selected with --profile, YTS_PROFILE
+// or yts config profile use. Model and temperature apply to the profile's
+// provider, or to the configured provider if the profile doesn't set one.
+type Profile struct {
+	Provider    string           `mapstructure:"provider,omitempty"`
+	Model       string           `mapstructure:"model,omitempty"`
+	Temperature *float64         `mapstructure:"temperature,omitempty"`
+	Summaries   SummaryConfig    `mapstructure:"summaries,omitempty"`
+	Transcripts TranscriptConfig `mapstructure:"transcripts,omitempty"`
+	Queries     QueryConfig      `mapstructure:"queries,omitempty"`
+	Chat        ChatConfig       `mapstructure:"chat,omitempty"`
+}
+
+// ProfileNamePattern matches valid profile names
+var ProfileNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// profilePromptPattern matches the prompt settings a profile can override,
+// relative to the profile
+var profilePromptPattern = regexp.MustCompile(`^(summaries\.[a-z0-9_-]+|transcripts|queries|chat)\.system_prompt$`)
+
+// ProfileNames returns the names of the configured profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsProfileKey reports whether key, relative to a profile, is a setting
+// profiles can override, e.g. model or summaries.short.system_prompt
+func IsProfileKey(key string) bool {
+	switch key {
+	case "provider", "model", "temperature":
+		return true
+	}
+	return profilePromptPattern.MatchString(key)
+}
+
+// Sources records where each setting's effective value came from, keyed by
+// dotted config key
+type Sources map[string]string
+
+// Of returns where key's value came from: "config file", "profile <name>",
+// "env <VAR>", "flag --<name>" or "default"
+func (s Sources) Of(key string) string {
+	if source, ok := s[key]; ok {
+		return source
+	}
+	return "default"
+}
+
+// applyProfile layers the named profile's settings over v
+func applyProfile(v *viper.Viper, name string, sources Sources) error {
+	raw, ok := v.Get("profiles." + name).(map[string]interface{})
+	if !ok {
+		var names []string
+		if profiles, ok := v.Get("profiles").(map[string]interface{}); ok {
+			for profile := range profiles {
+				names = append(names, profile)
+			}
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown profile: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	source := "profile " + name
+	settings := flatten(raw)
+
+	// Set the provider first, so model and temperature apply to it
+	if provider, ok := settings["provider"]; ok {
+		v.Set("provider", provider)
+		sources["provider"] = source
+	}
+	provider := v.GetString("provider")
+
+	for key, value := range settings {
+		switch {
+		case key == "provider":
+		case key == "model" || key == "temperature":
+			settingKey := providerKey(provider) + "." + key
+			v.Set(settingKey, value)
+			sources[settingKey] = source
+		case IsProfileKey(key):
+			v.Set(key, value)
+			sources[key] = source
+		default:
+			return fmt.Errorf("unsupported setting in profile %s: %s", name, key)
+		}
+	}
+	return nil
+}
+
+// CreateProfile adds a profile to the config file. It fails if the profile
+// already exists.
+func (l *Loader) CreateProfile(name string, profile Profile) error {
+	if !ProfileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name: %s (use lowercase letters, digits, - and _)", name)
+	}
+
+	return l.update(func(raw map[string]interface{}) error {
+		if getPath(raw, "profiles."+name) != nil {
+			return fmt.Errorf("profile already exists: %s", name)
+		}
+
+		settings := map[string]interface{}{}
+		for key, value := range structToMap(profile) {
+			setPath(settings, key, value)
+		}
+		setPath(raw, "profiles."+name, settings)
+		return nil
+	})
+}
+
+// DeleteProfile removes a profile from the config file, and stops using it
+// if it is the active profile
+func (l *Loader) DeleteProfile(name string) error {
+	return l.update(func(raw map[string]interface{}) error {
+		profiles, _ := raw["profiles"].(map[string]interface{})
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("unknown profile: %s", name)
+		}
+		delete(profiles, name)
+
+		if raw["profile"] == name {
+			raw["profile"] = ""
+		}
+		return nil
+	})
+}
+
+// UseProfile makes name the active profile in the config file. An empty
+// name stops using profiles.
+func (l *Loader) UseProfile(name string) error {
+	return l.update(func(raw map[string]interface{}) error {
+		if name != "" && getPath(raw, "profiles."+name) == nil {
+			return fmt.Errorf("unknown profile: %s", name)
+		}
+		raw["profile"] = name
+		return nil
+	})
+}
+
+// update applies fn to the raw config file and saves it. Working on the raw
+// JSON, rather than through viper, allows settings to be removed.
+func (l *Loader) update(fn func(raw map[string]interface{}) error) error {
+	// Create or migrate the config file first
+	if _, err := l.read(); err != nil {
+		return err
+	}
+
+	path, err := l.Path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := fn(raw); err != nil {
+		return err
+	}
+
+	updated, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := os.WriteFile(path, append(updated, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/term"
+)
+
+// ErrSecretNotFound is returned by a SecretStore that has no key for a provider
+var ErrSecretNotFound = errors.New("API key not found")
+
+// SecretStore is a backend that holds API keys, keyed by provider name
+type SecretStore interface {
+	// Name identifies the backend in messages and in secrets.order
+	Name() string
+	// Get returns ErrSecretNotFound when the backend has no key for provider
+	Get(provider string) (string, error)
+	Set(provider, apiKey string) error
+	Delete(provider string) error
+}
+
+// Secret store backends
+const (
+	SecretStoreEnv     = "env"
+	SecretStoreKeyring = "keyring"
+	SecretStoreFile    = "file"
+	SecretStoreMemory  = "memory"
+)
+
+const (
+	secretsFileName          = "apikeys.enc"
+	secretsPassphraseEnv     = "YTS_SECRETS_PASSPHRASE"
+	secretsFileIterations    = 600000 // PBKDF2-SHA256, per the OWASP recommendation
+	secretsFileSaltSize      = 16
+	secretsFileKeySize       = 32 // AES-256
+	secretsFileFormatVersion = 1
+)
+
+// WithSecretStores returns a copy of the config that looks API keys up in
+// stores, in order, instead of the stores named in Secrets. Stores are
+// identified by name, so only the first store with each name is used.
+func (c *Config) WithSecretStores(stores ...SecretStore) *Config {
+	copied := *c
+	copied.secretStores = append([]SecretStore{}, stores...)
+	return &copied
+}
+
+// KeyringStore keeps API keys in the OS keyring
+type KeyringStore struct {
+	service string
+}
+
+// NewKeyringStore returns a store backed by the OS keyring
+func NewKeyringStore() *KeyringStore {
+	return &KeyringStore{service: KeyringService}
+}
+
+func (s *KeyringStore) Name() string { return SecretStoreKeyring }
+
+func (s *KeyringStore) Get(provider string) (string, error) {
+	apiKey, err := keyring.Get(s.service, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keyring unavailable: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (s *KeyringStore) Set(provider, apiKey string) error {
+	return keyring.Set(s.service, provider, apiKey)
+}
+
+func (s *KeyringStore) Delete(provider string) error {
+	err := keyring.Delete(s.service, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrSecretNotFound
+	}
+	return err
+}
+
+// EnvStore reads API keys from YTS_<PROVIDER>_API_KEY environment variables.
+// It is read-only.
+type EnvStore struct {
+	lookupEnv func(key string) (string, bool)
+}
+
+// NewEnvStore returns a store that reads the environment
+func NewEnvStore() *EnvStore {
+	return &EnvStore{lookupEnv: os.LookupEnv}
+}
+
+// APIKeyEnvVar returns the environment variable holding a provider's API
+// key, e.g. YTS_CLAUDE_API_KEY
+func APIKeyEnvVar(provider string) string {
+	name := strings.ToUpper(strings.ReplaceAll(provider, "-", "_"))
+	return "YTS_" + name + "_API_KEY"
+}
+
+func (s *EnvStore) Name() string { return SecretStoreEnv }
+
+func (s *EnvStore) Get(provider string) (string, error) {
+	if apiKey, ok := s.lookupEnv(APIKeyEnvVar(provider)); ok && apiKey != "" {
+		return apiKey, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (s *EnvStore) Set(provider, apiKey string) error {
+	return fmt.Errorf("environment variables can't be written; export %s instead", APIKeyEnvVar(provider))
+}
+
+func (s *EnvStore) Delete(provider string) error {
+	return fmt.Errorf("environment variables can't be deleted; unset %s instead", APIKeyEnvVar(provider))
+}
+
+// MemoryStore holds API keys in memory, for programs that manage their own
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// NewMemoryStore returns a store holding a copy of keys, keyed by provider
+func NewMemoryStore(keys map[string]string) *MemoryStore {
+	s := &MemoryStore{keys: map[string]string{}}
+	for provider, apiKey := range keys {
+		s.keys[provider] = apiKey
+	}
+	return s
+}
+
+func (s *MemoryStore) Name() string { return SecretStoreMemory }
+
+func (s *MemoryStore) Get(provider string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	apiKey, ok := s.keys[provider]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return apiKey, nil
+}
+
+func (s *MemoryStore) Set(provider, apiKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[provider] = apiKey
+	return nil
+}
+
+func (s *MemoryStore) Delete(provider string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[provider]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(s.keys, provider)
+	return nil
+}
+
+// FileStore keeps API keys in a file encrypted with AES-256-GCM, using a key
+// derived from a passphrase with PBKDF2. The passphrase is read from
+// YTS_SECRETS_PASSPHRASE, or asked for when running in a terminal.
+type FileStore struct {
+	path       string
+	passphrase func(create bool) (string, error)
+
+	mu     sync.Mutex
+	cached string
+}
+
+// secretsFile is the on-disk format of a FileStore
+type secretsFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewFileStore returns a store backed by the encrypted file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path, passphrase: promptPassphrase}
+}
+
+// SecretsPath returns the encrypted API key file's location, next to the
+// config file
+func (c *Config) SecretsPath() (string, error) {
+	dir, err := c.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, secretsFileName), nil
+}
+
+func (s *FileStore) Name() string { return SecretStoreFile }
+
+func (s *FileStore) Get(provider string) (string, error) {
+	keys, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	apiKey, ok := keys[provider]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return apiKey, nil
+}
+
+func (s *FileStore) Set(provider, apiKey string) error {
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	keys[provider] = apiKey
+	return s.write(keys)
+}
+
+func (s *FileStore) Delete(provider string) error {
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := keys[provider]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(keys, provider)
+	return s.write(keys)
+}
+
+// read decrypts the file. A missing file holds no keys.
+func (s *FileStore) read() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	var file secretsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse API key file: %w", err)
+	}
+	if file.Version != secretsFileFormatVersion {
+		return nil, fmt.Errorf("unsupported API key file version: %d", file.Version)
+	}
+	// Never derive a weaker key than yts would write
+	if file.Iterations < secretsFileIterations {
+		return nil, fmt.Errorf("invalid API key file: %d key derivation iterations, need at least %d", file.Iterations, secretsFileIterations)
+	}
+	if len(file.Salt) < secretsFileSaltSize {
+		return nil, fmt.Errorf("invalid API key file: salt is %d bytes, need at least %d", len(file.Salt), secretsFileSaltSize)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newSecretsCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	// Open panics on a nonce of the wrong size
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid API key file: nonce is %d bytes, want %d", len(file.Nonce), gcm.NonceSize())
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		s.forgetPassphrase()
+		return nil, fmt.Errorf("failed to decrypt API key file: wrong passphrase or corrupted file")
+	}
+
+	keys := map[string]string{}
+	if err := json.Unmarshal(plaintext, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API key file: %w", err)
+	}
+	return keys, nil
+}
+
+// write encrypts keys with a fresh salt and nonce and saves them
+func (s *FileStore) write(keys map[string]string) error {
+	_, statErr := os.Stat(s.path)
+	passphrase, err := s.getPassphrase(errors.Is(statErr, fs.ErrNotExist))
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	file := secretsFile{
+		Version:    secretsFileFormatVersion,
+		Iterations: secretsFileIterations,
+		Salt:       make([]byte, secretsFileSaltSize),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newSecretsCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API key file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create API key file directory: %w", err)
+	}
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save API key file: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data, readable only by the user. The
+// data is written to a temporary file in the same directory and renamed
+// over path, so an interrupted write never leaves a truncated file.
+func writeFileAtomic(path string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// getPassphrase asks for the passphrase once and remembers it
+func (s *FileStore) getPassphrase(create bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != "" {
+		return s.cached, nil
+	}
+	passphrase, err := s.passphrase(create)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the API key file passphrase can't be empty")
+	}
+	s.cached = passphrase
+	return passphrase, nil
+}
+
+func (s *FileStore) forgetPassphrase() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = ""
+}
+
+// newSecretsCipher derives the file key from passphrase
+func newSecretsCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("failed to create cipher: empty salt")
+	}
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, secretsFileKeySize, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// promptPassphrase reads the passphrase from YTS_SECRETS_PASSPHRASE, or
+// from the terminal without echoing it. New files ask twice.
+func promptPassphrase(create bool) (string, error) {
+	if passphrase := os.Getenv(secretsPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("set %s to unlock the API key file", secretsPassphraseEnv)
+	}
+
+	prompt := "Passphrase for API key file: "
+	if create {
+		prompt = "New passphrase for API key file: "
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	if create {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(repeated) != string(passphrase) {
+			return "", fmt.Errorf("passphrases don't match")
+		}
+	}
+	return string(passphrase), nil
+}
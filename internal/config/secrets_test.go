@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestFileStore returns a FileStore in a temporary directory that uses
+// passphrase instead of prompting
+func newTestFileStore(t *testing.T, passphrase string) *FileStore {
+	t.Helper()
+	return &FileStore{
+		path: filepath.Join(t.TempDir(), secretsFileName),
+		passphrase: func(create bool) (string, error) {
+			return passphrase, nil
+		},
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	store := newTestFileStore(t, "correct horse")
+	if _, err := store.Get("claude"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Get() on a missing file error = %v, want ErrSecretNotFound", err)
+	}
+	if err := store.Set("claude", "sk-ant-test"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// A new store has to decrypt the file rather than use a cache
+	reopened := &FileStore{path: store.path, passphrase: store.passphrase}
+	if got, err := reopened.Get("claude"); err != nil || got != "sk-ant-test" {
+		t.Errorf("Get() = %q, %v, want sk-ant-test", got, err)
+	}
+
+	wrong := &FileStore{path: store.path, passphrase: func(bool) (string, error) { return "wrong", nil }}
+	if _, err := wrong.Get("claude"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Get() with the wrong passphrase error = %v", err)
+	}
+
+	if err := reopened.Delete("claude"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := reopened.Get("claude"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestFileStoreReplacesFile(t *testing.T) {
+	t.Parallel()
+
+	store := newTestFileStore(t, "correct horse")
+	// A key file left with loose permissions is tightened on the next write
+	if err := os.WriteFile(store.path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.write(map[string]string{"claude": "sk-ant-test"}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if err := store.Set("openai", "sk-test"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(store.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != secretsFileName {
+		t.Errorf("directory holds %v, want only %s", entries, secretsFileName)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(store.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("permissions = %o, want 600", perm)
+		}
+	}
+
+	reopened := &FileStore{path: store.path, passphrase: store.passphrase}
+	for provider, want := range map[string]string{"claude": "sk-ant-test", "openai": "sk-test"} {
+		if got, err := reopened.Get(provider); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v, want %s", provider, got, err, want)
+		}
+	}
+}
+
+func TestFileStoreRejectsWeakFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tamper  func(file *secretsFile)
+		wantErr string
+	}{
+		{
+			name:    "too few iterations",
+			tamper:  func(file *secretsFile) { file.Iterations = 1 },
+			wantErr: "key derivation iterations",
+		},
+		{
+			name:    "empty salt",
+			tamper:  func(file *secretsFile) { file.Salt = nil },
+			wantErr: "salt",
+		},
+		{
+			name:    "short nonce",
+			tamper:  func(file *secretsFile) { file.Nonce = file.Nonce[:4] },
+			wantErr: "nonce",
+		},
+		{
+			name:    "missing nonce",
+			tamper:  func(file *secretsFile) { file.Nonce = nil },
+			wantErr: "nonce",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newTestFileStore(t, "correct horse")
+			if err := store.Set("openai", "sk-test"); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			data, err := os.ReadFile(store.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var file secretsFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(&file)
+			if data, err = json.Marshal(file); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(store.path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			reopened := &FileStore{path: store.path, passphrase: store.passphrase}
+			_, err = reopened.Get("openai")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Get() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecretFilesFollowConfigPath(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "")
+	cfg, err := NewLoader(WithPath(path), WithEnv(fakeEnv(nil))).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if got, err := cfg.SecretsPath(); err != nil || got != filepath.Join(dir, secretsFileName) {
+		t.Errorf("SecretsPath() = %q, %v, want it in %s", got, err, dir)
+	}
+
+	keyManager := NewAPIKeyManager(cfg)
+	verifiedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := keyManager.RecordVerified("claude", "sk-ant-test", verifiedAt); err != nil {
+		t.Fatalf("RecordVerified() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, verifiedFileName)); err != nil {
+		t.Errorf("verification times not saved next to the config: %v", err)
+	}
+	if got, ok := keyManager.LastVerified("claude", "sk-ant-test"); !ok || !got.Equal(verifiedAt) {
+		t.Errorf("LastVerified() = %v, %v, want %v", got, ok, verifiedAt)
+	}
+	if _, ok := keyManager.LastVerified("claude", "sk-ant-replaced"); ok {
+		t.Error("LastVerified() reported a different key as verified")
+	}
+}
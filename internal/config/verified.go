@@ -0,0 +1,92 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const verifiedFileName = "apikeys-verified.json"
+
+// keyVerification records when an API key last passed yts apikey test. The
+// key itself isn't stored, only a fingerprint to tell when it was replaced.
+type keyVerification struct {
+	Fingerprint string    `json:"fingerprint"`
+	VerifiedAt  time.Time `json:"verified_at"`
+}
+
+// RecordVerified notes that apiKey worked for provider at t
+func (m *APIKeyManager) RecordVerified(provider, apiKey string, t time.Time) error {
+	path, err := m.verifiedPath()
+	if err != nil {
+		return err
+	}
+
+	records, err := readVerified(path)
+	if err != nil {
+		return err
+	}
+	records[provider] = keyVerification{Fingerprint: keyFingerprint(apiKey), VerifiedAt: t}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode verification times: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to save verification times: %w", err)
+	}
+	return nil
+}
+
+// LastVerified returns when apiKey last worked for provider. It reports
+// false if the key was never verified, or a different key was.
+func (m *APIKeyManager) LastVerified(provider, apiKey string) (time.Time, bool) {
+	path, err := m.verifiedPath()
+	if err != nil {
+		return time.Time{}, false
+	}
+	records, err := readVerified(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	record, ok := records[provider]
+	if !ok || record.Fingerprint != keyFingerprint(apiKey) {
+		return time.Time{}, false
+	}
+	return record.VerifiedAt, true
+}
+
+func (m *APIKeyManager) verifiedPath() (string, error) {
+	if m.dir == "" {
+		return "", errors.New("failed to get config directory")
+	}
+	return filepath.Join(m.dir, verifiedFileName), nil
+}
+
+func readVerified(path string) (map[string]keyVerification, error) {
+	records := map[string]keyVerification{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification times: %w", err)
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse verification times: %w", err)
+	}
+	return records, nil
+}
+
+// keyFingerprint returns a short hash that identifies a key without revealing it
+func keyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
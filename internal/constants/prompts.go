@@ -35,12 +35,61 @@ Preserve technical accuracy while ensuring readability. Include relevant quotes
 - Do not otherwise modify the content in any way`
 
 	QueryPrompt = `You are analyzing a YouTube video transcript.
-Video title: "{{title}}"
+Video title: "{{.Title}}"
 
-Question: {{query}}
+Question: {{.Query}}
 
 Provide a concise, accurate answer based ONLY on information contained in the transcript.
 If the transcript doesn't contain information to answer the question, clearly state this.
 Do not speculate beyond what's explicitly mentioned in the transcript.
 Reference specific details from the transcript to support your answer.`
+
+	TranslationPrompt = `Translate the following YouTube transcript into the language with code "{{.Language}}".
+- Translate the meaning faithfully, without summarizing or omitting anything
+- Keep the line structure and any leading timestamps exactly as they appear
+- Never add any additional commentary`
+
+	ChunkSummaryPrompt = `The following is one section of a longer YouTube transcript.
+Summarize this section so it can later be combined with summaries of the other sections.
+- Capture every main point, argument, example and conclusion in the section
+- Keep names, numbers and technical terms exactly as they appear
+- Do not add an introduction or conclusion about the video as a whole`
+
+	ReducePrompt = `The content below consists of summaries of consecutive sections of a single YouTube transcript, in order.
+Treat them together as the full transcript and follow the instructions below.`
+
+	SectionGroupPrompt = `The content below consists of summaries of consecutive sections of a longer YouTube transcript, in order.
+Combine them into one summary of this part of the transcript so it can later be combined with summaries of the other parts.
+- Start with a line naming the sections covered, e.g. "Sections 4-6 summary:"
+- Keep every main point, argument, example and conclusion
+- Keep names, numbers and technical terms exactly as they appear
+- Do not add an introduction or conclusion about the video as a whole`
+
+	ChatPrompt = `You are having a conversation about a YouTube video.
+Video title: "{{.Title}}"
+
+Answer the user's questions based ONLY on information contained in the transcript below.
+If the transcript doesn't contain the answer, clearly state this.
+Keep answers concise and reference specific details from the transcript.
+
+Transcript:
+{{.Transcript}}`
+
+	PlaylistOverviewPrompt = `The content below consists of summaries of every video in a YouTube playlist, in playlist order.
+Playlist title: "{{.Title}}"
+
+Write a course overview of the playlist as a whole:
+- Start with 2-3 sentences describing what the playlist covers and who it is for
+- Outline how the topics progress from video to video, grouping related videos
+- List the key takeaways of the playlist overall
+- Note any prerequisites or recommended order worth knowing about
+Refer to videos by their number and title. Do not repeat each summary in full.`
+
+	PlaylistGroupPrompt = `The content below consists of summaries of consecutive videos in a YouTube playlist, in playlist order.
+Playlist title: "{{.Title}}"
+
+Condense them so they can later be combined with the summaries of the other videos into a course overview:
+- Keep one short entry per video, starting with its number and title
+- Keep each video's main topics, key terms and takeaways
+- Do not write an overview of the playlist as a whole`
 )
@@ -0,0 +1,126 @@
+// Package feed reads YouTube channel upload feeds
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is YouTube's public feed endpoint
+const DefaultBaseURL = "https://www.youtube.com/feeds/videos.xml"
+
+// Feed is a channel's uploads feed. YouTube only includes the most recent
+// uploads, newest first.
+type Feed struct {
+	ChannelID string
+	Title     string
+	Entries   []Entry
+}
+
+// Entry is a single upload in a feed
+type Entry struct {
+	VideoID   string
+	Title     string
+	URL       string
+	Published time.Time
+}
+
+// atomFeed mirrors the Atom document YouTube serves
+type atomFeed struct {
+	ChannelID string `xml:"channelId"`
+	Title     string `xml:"title"`
+	Entries   []struct {
+		VideoID string `xml:"videoId"`
+		Title   string `xml:"title"`
+		Link    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Published time.Time `xml:"published"`
+	} `xml:"entry"`
+}
+
+// Client fetches channel feeds
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a feed client. An empty baseURL uses DefaultBaseURL.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Fetch retrieves the uploads feed for a channel
+func (c *Client) Fetch(ctx context.Context, channelID string) (*Feed, error) {
+	feedURL := c.baseURL + "?channel_id=" + url.QueryEscape(channelID)
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("channel not found: %s", channelID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status: %d", resp.StatusCode)
+	}
+
+	var doc atomFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	feed := &Feed{ChannelID: channelID, Title: doc.Title}
+	for _, e := range doc.Entries {
+		if e.VideoID == "" {
+			continue
+		}
+		entry := Entry{
+			VideoID:   e.VideoID,
+			Title:     e.Title,
+			URL:       e.Link.Href,
+			Published: e.Published,
+		}
+		if entry.URL == "" {
+			entry.URL = "https://www.youtube.com/watch?v=" + e.VideoID
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed, nil
+}
+
+// ChannelID returns the channel ID from a channel ID or a
+// youtube.com/channel/<id> URL
+func ChannelID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if _, rest, ok := strings.Cut(s, "/channel/"); ok {
+		if i := strings.IndexAny(rest, "/?"); i >= 0 {
+			rest = rest[:i]
+		}
+		s = rest
+	}
+	if !strings.HasPrefix(s, "UC") || len(s) != 24 {
+		return "", fmt.Errorf("not a channel ID or /channel/ URL: %s", s)
+	}
+	return s, nil
+}
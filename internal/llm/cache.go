@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// ResponseCache stores completed generations so identical requests can be replayed
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Put(key string, response *CachedResponse) error
+}
+
+// CachedResponse is a completed generation, kept as the original stream
+// chunks so a replay looks the same to the callback as a live response
+type CachedResponse struct {
+	Chunks []string `json:"chunks"`
+	Result Result   `json:"result"`
+}
+
+// cachingProvider replays responses for requests it has seen before
+type cachingProvider struct {
+	provider    Provider
+	cache       ResponseCache
+	name        string
+	model       string
+	temperature float64
+}
+
+// WithResponseCache wraps p so identical requests to the same provider, model
+// and temperature are answered from cache
+func WithResponseCache(p Provider, cfg *config.Config, cache ResponseCache) Provider {
+	model, temperature := activeModel(cfg)
+	return &cachingProvider{
+		provider:    p,
+		cache:       cache,
+		name:        cfg.Provider,
+		model:       model,
+		temperature: temperature,
+	}
+}
+
+func (p *cachingProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func (p *cachingProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	key := p.key(r)
+	if cached, ok := p.cache.Get(key); ok {
+		for _, chunk := range cached.Chunks {
+			callback(chunk)
+		}
+		result := cached.Result
+		return &result, nil
+	}
+
+	var chunks []string
+	result, err := p.provider.Generate(ctx, r, func(chunk string) {
+		chunks = append(chunks, chunk)
+		callback(chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Caching is best-effort; a failed write shouldn't fail the request
+	_ = p.cache.Put(key, &CachedResponse{Chunks: chunks, Result: *result})
+
+	return result, nil
+}
+
+// key hashes everything that influences the generated output
+func (p *cachingProvider) key(r *Request) string {
+	temperature := p.temperature
+	if r.Options.Temperature != nil {
+		temperature = *r.Options.Temperature
+	}
+
+	data, _ := json.Marshal(struct {
+		Provider    string    `json:"provider"`
+		Model       string    `json:"model"`
+		Temperature float64   `json:"temperature"`
+		System      string    `json:"system"`
+		Messages    []Message `json:"messages"`
+		MaxTokens   int       `json:"max_tokens"`
+		Stop        []string  `json:"stop"`
+	}{p.name, p.model, temperature, r.System, r.Messages, r.Options.MaxTokens, r.Options.Stop})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// activeModel returns the model and temperature configured for the active provider
+func activeModel(cfg *config.Config) (string, float64) {
+	switch cfg.Provider {
+	case "lmstudio":
+		return cfg.Providers.LMStudio.Model, valueOrZero(cfg.Providers.LMStudio.Temperature)
+	case "ollama":
+		return cfg.Providers.Ollama.Model, valueOrZero(cfg.Providers.Ollama.Temperature)
+	case "claude":
+		return cfg.Providers.Claude.Model, cfg.Providers.Claude.Temperature
+	case "openai":
+		return cfg.Providers.OpenAI.Model, cfg.Providers.OpenAI.Temperature
+	case "gemini":
+		return cfg.Providers.Gemini.Model, cfg.Providers.Gemini.Temperature
+	}
+	if endpoint, ok := cfg.Endpoint(cfg.Provider); ok {
+		return endpoint.Model, valueOrZero(endpoint.Temperature)
+	}
+	return cfg.ActiveModel(), 0
+}
+
+// valueOrZero returns *v, or 0 when the temperature isn't configured
+func valueOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// mapCache is a ResponseCache that records the keys it is given
+type mapCache map[string]*CachedResponse
+
+func (c mapCache) Get(key string) (*CachedResponse, bool) {
+	response, ok := c[key]
+	return response, ok
+}
+
+func (c mapCache) Put(key string, response *CachedResponse) error {
+	c[key] = response
+	return nil
+}
+
+// countingProvider replies with reply and counts the requests it receives
+type countingProvider struct {
+	reply string
+	calls int
+}
+
+func (p *countingProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	p.calls++
+	callback(p.reply)
+	return &Result{}, nil
+}
+
+func (p *countingProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func TestResponseCacheKeyIncludesTemperature(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"lmstudio", "ollama"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := mapCache{}
+			provider := &countingProvider{reply: "summary"}
+			for _, temperature := range []float64{0.2, 0.8, 0.2} {
+				cfg := config.Default()
+				cfg.Provider = name
+				cfg.Providers.LMStudio.Temperature = Float64(temperature)
+				cfg.Providers.Ollama.Temperature = Float64(temperature)
+
+				err := WithResponseCache(provider, cfg, cache).Stream(context.Background(), "system", "transcript", func(string) {})
+				if err != nil {
+					t.Fatalf("Stream() error = %v", err)
+				}
+			}
+
+			// The repeated temperature is answered from cache
+			if len(cache) != 2 {
+				t.Errorf("cache keys = %d, want one per temperature", len(cache))
+			}
+			if provider.calls != 2 {
+				t.Errorf("provider calls = %d, want 2", provider.calls)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,30 +12,31 @@ import (
 	"time"
 
 	"github.com/conormkelly/yts-cli/internal/config"
-	"github.com/zalando/go-keyring"
 )
 
 const (
-	claudeAPIURL         = "https://api.anthropic.com/v1/messages"
-	claudeRetryBaseDelay = 1 * time.Second
+	claudeMessagesPath = "/v1/messages" // Appended to the configured base URL
 )
 
 type ClaudeProvider struct {
+	url         string
+	headers     map[string]string // Extra headers, e.g. for a gateway
 	model       string
 	apiKey      string
 	maxTokens   int
-	maxRetries  int
 	temperature float64
+	retry       retryPolicy
 	client      *http.Client
 }
 
 type ClaudeRequest struct {
-	Model       string          `json:"model"`
-	Messages    []ClaudeMessage `json:"messages"`
-	System      string          `json:"system,omitempty"`
-	Stream      bool            `json:"stream"`
-	MaxTokens   int             `json:"max_tokens,omitempty"`
-	Temperature float64         `json:"temperature,omitempty"`
+	Model         string          `json:"model"`
+	Messages      []ClaudeMessage `json:"messages"`
+	System        string          `json:"system,omitempty"`
+	Stream        bool            `json:"stream"`
+	MaxTokens     int             `json:"max_tokens,omitempty"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
 }
 
 type ClaudeMessage struct {
@@ -42,81 +44,120 @@ type ClaudeMessage struct {
 	Content string `json:"content"`
 }
 
+type ClaudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
 type ClaudeStreamEvent struct {
 	Type string `json:"type"`
 
 	// For message_start
 	Message *struct {
-		ID      string `json:"id"`
-		Role    string `json:"role"`
-		Content []any  `json:"content"`
+		ID      string      `json:"id"`
+		Role    string      `json:"role"`
+		Model   string      `json:"model"`
+		Content []any       `json:"content"`
+		Usage   ClaudeUsage `json:"usage"`
 	} `json:"message,omitempty"`
 
-	// For content_block_delta
+	// For content_block_delta and message_delta
 	Index int `json:"index,omitempty"`
 	Delta *struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
 	} `json:"delta,omitempty"`
+
+	// For message_delta
+	Usage *ClaudeUsage `json:"usage,omitempty"`
 }
 
 func NewClaudeProvider(cfg *config.Config) (*ClaudeProvider, error) {
-	// Get API key from keyring
-	apiKey, err := keyring.Get(config.KeyringService, "claude")
+	apiKey, err := config.NewAPIKeyManager(cfg).GetAPIKey("claude")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get Claude API key from keyring: %w", err)
+		return nil, fmt.Errorf("failed to get Claude API key: %w", err)
 	}
+	return newClaudeProvider(cfg, apiKey), nil
+}
 
+// newClaudeProvider creates a provider that authenticates with apiKey
+func newClaudeProvider(cfg *config.Config, apiKey string) *ClaudeProvider {
 	return &ClaudeProvider{
+		url:         strings.TrimSuffix(cfg.Providers.Claude.BaseURL, "/") + claudeMessagesPath,
+		headers:     cfg.Providers.Claude.Headers,
 		model:       cfg.Providers.Claude.Model,
 		apiKey:      apiKey,
 		temperature: cfg.Providers.Claude.Temperature,
 		maxTokens:   cfg.Providers.Claude.MaxTokens,
-		maxRetries:  cfg.Providers.Claude.MaxRetries,
+		retry:       newRetryPolicy(cfg.Providers.Claude.MaxRetries),
 		client: &http.Client{
 			Timeout: time.Duration(cfg.Providers.Claude.TimeoutSecs) * time.Second,
 		},
-	}, nil
+	}
+}
+
+func (p *ClaudeProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
 }
 
-func (p *ClaudeProvider) Stream(systemPrompt string, transcript string, callback func(string)) error {
+func (p *ClaudeProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	claudeMessages := make([]ClaudeMessage, 0, len(r.Messages))
+	for _, m := range r.Messages {
+		claudeMessages = append(claudeMessages, ClaudeMessage{Role: m.Role, Content: m.Content})
+	}
+
 	req := ClaudeRequest{
-		Model: p.model,
-		Messages: []ClaudeMessage{
-			{Role: "user", Content: transcript},
-		},
-		System:      systemPrompt,
-		Stream:      true,
-		MaxTokens:   p.maxTokens,
-		Temperature: p.temperature,
+		Model:         p.model,
+		Messages:      claudeMessages,
+		System:        r.System,
+		Stream:        true,
+		MaxTokens:     p.maxTokens,
+		Temperature:   &p.temperature,
+		StopSequences: r.Options.Stop,
+	}
+	if r.Options.MaxTokens > 0 {
+		req.MaxTokens = r.Options.MaxTokens
+	}
+	if r.Options.Temperature != nil {
+		req.Temperature = r.Options.Temperature
 	}
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return nil, fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	request, err := http.NewRequest("POST", claudeAPIURL, bytes.NewBuffer(jsonData))
+	return p.retry.generate(ctx, callback, func(callback func(string)) (*Result, error) {
+		return p.generateOnce(ctx, jsonData, callback)
+	})
+}
+
+// generateOnce makes a single streaming request to the Messages API
+func (p *ClaudeProvider) generateOnce(ctx context.Context, jsonData []byte, callback func(string)) (*Result, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(jsonData))
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set headers
 	request.Header.Set("x-api-key", p.apiKey)
 	request.Header.Set("anthropic-version", "2023-06-01")
 	request.Header.Set("content-type", "application/json")
+	setHeaders(request, p.headers)
 
 	resp, err := p.client.Do(request)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("Claude API error (%d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError("Claude", resp)
 	}
 
+	result := &Result{Model: p.model}
+
 	// Read the stream line by line
 	reader := bufio.NewReader(resp.Body)
 	for {
@@ -125,7 +166,7 @@ func (p *ClaudeProvider) Stream(systemPrompt string, transcript string, callback
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading stream: %w", err)
+			return nil, fmt.Errorf("error reading stream: %w", err)
 		}
 
 		line = strings.TrimSpace(line)
@@ -140,14 +181,14 @@ func (p *ClaudeProvider) Stream(systemPrompt string, transcript string, callback
 				// Get the next line which should contain the error details
 				errLine, err := reader.ReadString('\n')
 				if err != nil {
-					return fmt.Errorf("error reading error details: %w", err)
+					return nil, fmt.Errorf("error reading error details: %w", err)
 				}
 				// Try to parse error details if present
 				if strings.HasPrefix(errLine, "data:") {
 					errData := strings.TrimSpace(strings.TrimPrefix(errLine, "data:"))
-					return fmt.Errorf("Claude streaming error: %s", errData)
+					return nil, claudeStreamError(errData)
 				}
-				return fmt.Errorf("Claude streaming error (no details available)")
+				return nil, fmt.Errorf("Claude streaming error (no details available)")
 			}
 			continue
 		}
@@ -162,17 +203,56 @@ func (p *ClaudeProvider) Stream(systemPrompt string, transcript string, callback
 
 		var event ClaudeStreamEvent
 		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			return fmt.Errorf("error parsing stream event: %w", err)
+			return nil, fmt.Errorf("error parsing stream event: %w", err)
 		}
 
 		// Handle different event types
 		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				if event.Message.Model != "" {
+					result.Model = event.Message.Model
+				}
+				result.Usage.InputTokens = event.Message.Usage.InputTokens
+			}
 		case "content_block_delta":
 			if event.Delta != nil && event.Delta.Type == "text_delta" {
 				callback(event.Delta.Text)
 			}
+		case "message_delta":
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				result.StopReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				result.Usage.OutputTokens = event.Usage.OutputTokens
+			}
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// claudeStreamError converts an error event sent mid-stream into an APIError,
+// using the status code the same error would have had as an HTTP response
+func claudeStreamError(data string) error {
+	var payload struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return fmt.Errorf("Claude streaming error: %s", data)
+	}
+
+	statusCode := http.StatusBadRequest
+	switch payload.Error.Type {
+	case "overloaded_error":
+		statusCode = 529
+	case "rate_limit_error":
+		statusCode = http.StatusTooManyRequests
+	case "api_error":
+		statusCode = http.StatusInternalServerError
+	}
+
+	return &APIError{Provider: "Claude", StatusCode: statusCode, Body: data}
 }
@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// CompatibleProvider talks to any server that implements the OpenAI Chat
+// Completions API. It backs LM Studio and user-defined endpoints.
+type CompatibleProvider struct {
+	name        string // Used in error messages
+	url         string
+	headers     map[string]string
+	model       string
+	apiKey      string // Empty sends no Authorization header
+	temperature *float64
+	topP        *float64
+	maxTokens   int
+	errorHelp   string // Added to errors reported mid-stream
+	retry       retryPolicy
+	client      *http.Client
+}
+
+func NewLMStudioProvider(baseURL string, model string) *CompatibleProvider {
+	return &CompatibleProvider{
+		name:      "LM Studio",
+		url:       baseURL + "/v1" + openaiChatPath,
+		model:     model,
+		errorHelp: "check the Developer tab > Developer Logs for details",
+		client:    http.DefaultClient,
+	}
+}
+
+// NewCompatibleProvider creates a provider for the named endpoint, looking
+// up its API key if it references one
+func NewCompatibleProvider(cfg *config.Config, name string) (*CompatibleProvider, error) {
+	endpoint, ok := cfg.Endpoint(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown endpoint: %s", name)
+	}
+	if endpoint.Type != config.EndpointTypeOpenAICompatible {
+		return nil, fmt.Errorf("endpoint %s has unsupported type: %s (supported: %s)", name, endpoint.Type, config.EndpointTypeOpenAICompatible)
+	}
+	if endpoint.BaseURL == "" {
+		return nil, fmt.Errorf("endpoint %s has no base_url; set it with 'yts config set endpoints.%s.base_url <url>'", name, name)
+	}
+
+	apiKey := ""
+	if endpoint.APIKeyRef != "" {
+		var err error
+		apiKey, err = config.NewAPIKeyManager(cfg).GetAPIKey(endpoint.APIKeyRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get API key for endpoint %s: %w", name, err)
+		}
+	}
+	return newCompatibleProvider(name, endpoint, apiKey), nil
+}
+
+// newCompatibleProvider creates a provider for endpoint that authenticates with apiKey
+func newCompatibleProvider(name string, endpoint config.Endpoint, apiKey string) *CompatibleProvider {
+	return &CompatibleProvider{
+		name:        name,
+		url:         strings.TrimSuffix(endpoint.BaseURL, "/") + openaiChatPath,
+		headers:     endpoint.Headers,
+		model:       endpoint.Model,
+		apiKey:      apiKey,
+		temperature: endpoint.Temperature,
+		topP:        endpoint.TopP,
+		maxTokens:   endpoint.MaxTokens,
+		retry:       newRetryPolicy(endpoint.MaxRetries),
+		client: &http.Client{
+			Timeout: time.Duration(endpoint.TimeoutSecs) * time.Second,
+		},
+	}
+}
+
+type CompletionRequest struct {
+	Model         string               `json:"model"`
+	Messages      []Message            `json:"messages"`
+	Stream        bool                 `json:"stream"`
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	Stop          []string             `json:"stop,omitempty"`
+}
+
+type StreamResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *OpenAIUsage `json:"usage,omitempty"`
+}
+
+func (p *CompatibleProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func (p *CompatibleProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	req := CompletionRequest{
+		Model:         p.model,
+		Messages:      append([]Message{{Role: "system", Content: r.System}}, r.Messages...),
+		Stream:        true,
+		StreamOptions: &OpenAIStreamOptions{IncludeUsage: true},
+		MaxTokens:     p.maxTokens,
+		Temperature:   p.temperature,
+		TopP:          p.topP,
+		Stop:          r.Options.Stop,
+	}
+	if r.Options.MaxTokens > 0 {
+		req.MaxTokens = r.Options.MaxTokens
+	}
+	if r.Options.Temperature != nil {
+		req.Temperature = r.Options.Temperature
+	}
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	return p.retry.generate(ctx, callback, func(callback func(string)) (*Result, error) {
+		return p.generateOnce(ctx, jsonData, callback)
+	})
+}
+
+// generateOnce makes a single streaming chat completions request
+func (p *CompatibleProvider) generateOnce(ctx context.Context, jsonData []byte, callback func(string)) (*Result, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	setHeaders(request, p.headers)
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(p.name, resp)
+	}
+
+	result := &Result{Model: p.model}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading stream: %w", err)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "event: error" {
+			if p.errorHelp != "" {
+				return nil, fmt.Errorf("%s error - %s", p.name, p.errorHelp)
+			}
+			return nil, fmt.Errorf("%s reported an error mid-stream", p.name)
+		}
+
+		data, ok := sseData(line)
+		if !ok {
+			continue
+		}
+
+		var streamResp StreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return nil, fmt.Errorf("error parsing stream response: %w", err)
+		}
+
+		if streamResp.Model != "" {
+			result.Model = streamResp.Model
+		}
+		if streamResp.Usage != nil {
+			result.Usage = Usage{
+				InputTokens:  streamResp.Usage.PromptTokens,
+				OutputTokens: streamResp.Usage.CompletionTokens,
+			}
+		}
+
+		if len(streamResp.Choices) > 0 {
+			content := streamResp.Choices[0].Delta.Content
+			if content != "" {
+				callback(content)
+			}
+			if reason := streamResp.Choices[0].FinishReason; reason != "" {
+				result.StopReason = openAIStopReason(reason)
+			}
+		}
+	}
+
+	return result, nil
+}
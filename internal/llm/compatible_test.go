@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// newStreamServer returns a server that answers chat completions with body
+func newStreamServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCompatibleProviderStream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "OpenAI style",
+			body: `data: {"model":"served-model","choices":[{"delta":{"content":"Hello"}}]}
+
+data: {"choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2}}
+
+data: [DONE]
+`,
+		},
+		{
+			name: "OpenRouter comments and other fields",
+			body: `: OPENROUTER PROCESSING
+
+: OPENROUTER PROCESSING
+id: 1
+event: message
+data:{"model":"served-model","choices":[{"delta":{"content":"Hello"}}]}
+retry: 1000
+
+data:   {"choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}
+data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2}}
+data:
+data:[DONE]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newStreamServer(t, tt.body)
+			provider := newCompatibleProvider("test", config.Endpoint{BaseURL: srv.URL + "/v1", Model: "requested-model"}, "")
+
+			var text strings.Builder
+			result, err := provider.Generate(context.Background(), &Request{
+				System:   "system",
+				Messages: []Message{{Role: RoleUser, Content: "hi"}},
+			}, func(chunk string) {
+				text.WriteString(chunk)
+			})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if text.String() != "Hello world" {
+				t.Errorf("text = %q, want Hello world", text.String())
+			}
+			if result.Model != "served-model" {
+				t.Errorf("Model = %q, want served-model", result.Model)
+			}
+			if result.StopReason != StopReasonEndTurn {
+				t.Errorf("StopReason = %q, want %q", result.StopReason, StopReasonEndTurn)
+			}
+			if result.Usage.InputTokens != 12 || result.Usage.OutputTokens != 2 {
+				t.Errorf("Usage = %+v, want 12 in and 2 out", result.Usage)
+			}
+		})
+	}
+}
+
+func TestCompatibleProviderStreamError(t *testing.T) {
+	t.Parallel()
+
+	srv := newStreamServer(t, `data: {"choices":[{"delta":{"content":"Hel"}}]}
+
+event: error
+data: {"error":{"message":"model crashed"}}
+`)
+	provider := newCompatibleProvider("test", config.Endpoint{BaseURL: srv.URL + "/v1"}, "")
+
+	err := provider.Stream(context.Background(), "system", "hi", func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "test reported an error mid-stream") {
+		t.Errorf("Stream() error = %v, want a mid-stream error", err)
+	}
+}
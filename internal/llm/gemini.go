@@ -0,0 +1,292 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+const (
+	geminiStreamPath = "/models/%s:streamGenerateContent?alt=sse" // Appended to the configured base URL
+)
+
+type GeminiProvider struct {
+	url         string
+	model       string
+	apiKey      string
+	maxTokens   int
+	temperature float64
+	retry       retryPolicy
+	client      *http.Client
+}
+
+type GeminiRequest struct {
+	SystemInstruction *GeminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []GeminiContent        `json:"contents"`
+	GenerationConfig  GeminiGenerationConfig `json:"generationConfig"`
+}
+
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+type GeminiPart struct {
+	Text string `json:"text"`
+}
+
+type GeminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+type GeminiSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked"`
+}
+
+type GeminiStreamResponse struct {
+	Candidates []struct {
+		Content       GeminiContent        `json:"content"`
+		FinishReason  string               `json:"finishReason"`
+		SafetyRatings []GeminiSafetyRating `json:"safetyRatings"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason   string               `json:"blockReason"`
+		SafetyRatings []GeminiSafetyRating `json:"safetyRatings"`
+	} `json:"promptFeedback,omitempty"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// BlockedError is returned when a provider refuses a prompt or stops a
+// response because of its safety or content policies
+type BlockedError struct {
+	Provider   string
+	Reason     string   // The provider's reason, e.g. SAFETY
+	Categories []string // Categories that triggered the block, if reported
+	Prompt     bool     // The prompt was blocked, rather than the response
+}
+
+func (e *BlockedError) Error() string {
+	what := "response"
+	if e.Prompt {
+		what = "prompt"
+	}
+	msg := fmt.Sprintf("%s blocked the %s (%s)", e.Provider, what, strings.ToLower(e.Reason))
+	if len(e.Categories) > 0 {
+		msg += ": " + strings.Join(e.Categories, ", ")
+	}
+	return msg
+}
+
+func NewGeminiProvider(cfg *config.Config) (*GeminiProvider, error) {
+	apiKey, err := config.NewAPIKeyManager(cfg).GetAPIKey("gemini")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Gemini API key: %w", err)
+	}
+	return newGeminiProvider(cfg, apiKey), nil
+}
+
+// newGeminiProvider creates a provider that authenticates with apiKey
+func newGeminiProvider(cfg *config.Config, apiKey string) *GeminiProvider {
+	return &GeminiProvider{
+		url:         strings.TrimSuffix(cfg.Providers.Gemini.BaseURL, "/") + fmt.Sprintf(geminiStreamPath, url.PathEscape(cfg.Providers.Gemini.Model)),
+		model:       cfg.Providers.Gemini.Model,
+		apiKey:      apiKey,
+		temperature: cfg.Providers.Gemini.Temperature,
+		maxTokens:   cfg.Providers.Gemini.MaxOutputTokens,
+		retry:       newRetryPolicy(cfg.Providers.Gemini.MaxRetries),
+		client: &http.Client{
+			Timeout: time.Duration(cfg.Providers.Gemini.TimeoutSecs) * time.Second,
+		},
+	}
+}
+
+func (p *GeminiProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	contents := make([]GeminiContent, 0, len(r.Messages))
+	for _, m := range r.Messages {
+		// Gemini calls the assistant "model"
+		role := m.Role
+		if role == RoleAssistant {
+			role = "model"
+		}
+		contents = append(contents, GeminiContent{Role: role, Parts: []GeminiPart{{Text: m.Content}}})
+	}
+
+	req := GeminiRequest{
+		Contents: contents,
+		GenerationConfig: GeminiGenerationConfig{
+			Temperature:     &p.temperature,
+			MaxOutputTokens: p.maxTokens,
+			StopSequences:   r.Options.Stop,
+		},
+	}
+	if r.System != "" {
+		req.SystemInstruction = &GeminiContent{Parts: []GeminiPart{{Text: r.System}}}
+	}
+	if r.Options.MaxTokens > 0 {
+		req.GenerationConfig.MaxOutputTokens = r.Options.MaxTokens
+	}
+	if r.Options.Temperature != nil {
+		req.GenerationConfig.Temperature = r.Options.Temperature
+	}
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	return p.retry.generate(ctx, callback, func(callback func(string)) (*Result, error) {
+		return p.generateOnce(ctx, jsonData, callback)
+	})
+}
+
+// generateOnce makes a single streamGenerateContent request
+func (p *GeminiProvider) generateOnce(ctx context.Context, jsonData []byte, callback func(string)) (*Result, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	request.Header.Set("x-goog-api-key", p.apiKey)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError("Gemini", resp)
+	}
+
+	result := &Result{Model: p.model}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading stream: %w", err)
+		}
+
+		data, ok := sseData(line)
+		if !ok {
+			continue
+		}
+
+		var streamResp GeminiStreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return nil, fmt.Errorf("error parsing stream response: %w", err)
+		}
+
+		if streamResp.ModelVersion != "" {
+			result.Model = streamResp.ModelVersion
+		}
+		if streamResp.UsageMetadata != nil {
+			result.Usage = Usage{
+				InputTokens:  streamResp.UsageMetadata.PromptTokenCount,
+				OutputTokens: streamResp.UsageMetadata.CandidatesTokenCount,
+			}
+		}
+
+		// A blocked prompt gets feedback instead of candidates
+		if feedback := streamResp.PromptFeedback; feedback != nil && feedback.BlockReason != "" {
+			return nil, &BlockedError{
+				Provider:   "Gemini",
+				Reason:     feedback.BlockReason,
+				Categories: blockedCategories(feedback.SafetyRatings),
+				Prompt:     true,
+			}
+		}
+
+		if len(streamResp.Candidates) == 0 {
+			continue
+		}
+		candidate := streamResp.Candidates[0]
+		for _, part := range candidate.Content.Parts {
+			if part.Text != "" {
+				callback(part.Text)
+			}
+		}
+		if candidate.FinishReason == "" {
+			continue
+		}
+		if geminiBlockReasons[candidate.FinishReason] {
+			return nil, &BlockedError{
+				Provider:   "Gemini",
+				Reason:     candidate.FinishReason,
+				Categories: blockedCategories(candidate.SafetyRatings),
+			}
+		}
+		result.StopReason = geminiStopReason(candidate.FinishReason)
+	}
+
+	return result, nil
+}
+
+// geminiBlockReasons are the finish reasons that mean the response was
+// withheld or cut off by a content policy
+var geminiBlockReasons = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"IMAGE_SAFETY":       true,
+}
+
+// blockedCategories lists the harm categories that were blocked, or rated
+// at least medium probability when none are marked blocked
+func blockedCategories(ratings []GeminiSafetyRating) []string {
+	var blocked, likely []string
+	for _, rating := range ratings {
+		category := strings.ToLower(strings.TrimPrefix(rating.Category, "HARM_CATEGORY_"))
+		category = strings.ReplaceAll(category, "_", " ")
+		switch {
+		case rating.Blocked:
+			blocked = append(blocked, category)
+		case rating.Probability == "MEDIUM" || rating.Probability == "HIGH":
+			likely = append(likely, fmt.Sprintf("%s (%s)", category, strings.ToLower(rating.Probability)))
+		}
+	}
+	if len(blocked) > 0 {
+		return blocked
+	}
+	return likely
+}
+
+// geminiStopReason maps Gemini finish reasons onto the common stop reasons
+func geminiStopReason(reason string) string {
+	switch reason {
+	case "STOP":
+		return StopReasonEndTurn
+	case "MAX_TOKENS":
+		return StopReasonMaxTokens
+	default:
+		return strings.ToLower(reason)
+	}
+}
@@ -0,0 +1,56 @@
+package llm
+
+import "context"
+
+// DefaultConcurrency returns how many requests a provider should be sent at
+// once. Local servers such as LM Studio and Ollama generally process one
+// request at a time, so parallel requests only queue up or fail.
+func DefaultConcurrency(providerName string) int {
+	switch providerName {
+	case "lmstudio", "ollama":
+		return 1
+	default:
+		return 4
+	}
+}
+
+// Limiter bounds the number of in-flight requests across every provider it
+// wraps, so providers created for different models share one limit
+type Limiter struct {
+	sem chan struct{}
+}
+
+// NewLimiter returns a limiter that lets at most n requests run at once.
+// Callers over the limit wait for a slot or for their context to be cancelled.
+func NewLimiter(n int) *Limiter {
+	if n <= 0 {
+		n = 1
+	}
+	return &Limiter{sem: make(chan struct{}, n)}
+}
+
+// Limit wraps p so its requests count against the limiter's slots
+func (l *Limiter) Limit(p Provider) Provider {
+	return &limitedProvider{provider: p, limiter: l}
+}
+
+// limitedProvider waits for a slot in its limiter before each request
+type limitedProvider struct {
+	provider Provider
+	limiter  *Limiter
+}
+
+func (l *limitedProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, l, systemPrompt, transcript, callback)
+}
+
+func (l *limitedProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	select {
+	case l.limiter.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-l.limiter.sem }()
+
+	return l.provider.Generate(ctx, r, callback)
+}
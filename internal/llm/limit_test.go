@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowProvider takes a moment to answer, recording the most requests it
+// saw running at once through its shared counter
+type slowProvider struct {
+	mu      *sync.Mutex
+	running *int
+	peak    *int
+}
+
+func (p slowProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	p.mu.Lock()
+	*p.running++
+	*p.peak = max(*p.peak, *p.running)
+	p.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	p.mu.Lock()
+	*p.running--
+	p.mu.Unlock()
+	return &Result{}, nil
+}
+
+func (p slowProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func TestLimiterSharedAcrossProviders(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var running, peak int
+	limiter := NewLimiter(2)
+	providers := []Provider{
+		limiter.Limit(slowProvider{&mu, &running, &peak}),
+		limiter.Limit(slowProvider{&mu, &running, &peak}),
+		limiter.Limit(slowProvider{&mu, &running, &peak}),
+	}
+
+	var wg sync.WaitGroup
+	for i := range 12 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := providers[i%len(providers)].Stream(context.Background(), "system", "input", func(string) {}); err != nil {
+				t.Errorf("Stream() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("peak concurrent requests = %d, want 2 across all providers", peak)
+	}
+}
+
+func TestLimiterCancelled(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var running, peak int
+	limiter := NewLimiter(1)
+	limiter.sem <- struct{}{} // Take the only slot
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := limiter.Limit(slowProvider{&mu, &running, &peak}).Stream(ctx, "system", "input", func(string) {})
+	if err != context.Canceled {
+		t.Errorf("Stream() error = %v, want %v", err, context.Canceled)
+	}
+	if peak != 0 {
+		t.Error("a cancelled request reached the provider")
+	}
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,52 +24,70 @@ func NewOllamaProvider(baseURL, model string) *OllamaProvider {
 }
 
 type OllamaRequest struct {
-	Model   string                 `json:"model"`
-	Prompt  string                 `json:"prompt"`
-	Stream  bool                   `json:"stream"`
-	Options map[string]interface{} `json:"options,omitempty"`
+	Model    string                 `json:"model"`
+	Messages []Message              `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
 }
 
 type OllamaResponse struct {
-	Model     string `json:"model"`
-	CreatedAt string `json:"created_at"`
-	Response  string `json:"response"`
-	Done      bool   `json:"done"`
+	Model           string  `json:"model"`
+	CreatedAt       string  `json:"created_at"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason,omitempty"`
+	PromptEvalCount int     `json:"prompt_eval_count,omitempty"`
+	EvalCount       int     `json:"eval_count,omitempty"`
 }
 
-func (p *OllamaProvider) Stream(systemPrompt string, transcript string, callback func(string)) error {
-	// Combine system prompt and transcript into a single prompt
-	// Using a clear delimiter to separate system context from user input
+func (p *OllamaProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func (p *OllamaProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
 	req := OllamaRequest{
-		Model: p.model, // Use provider's configured model
-		Prompt: fmt.Sprintf("### System Instructions:\n%s\n\n### Content to Process:\n%s",
-			systemPrompt, transcript),
-		Stream: true,
+		Model:    p.model, // Use provider's configured model
+		Messages: append([]Message{{Role: "system", Content: r.System}}, r.Messages...),
+		Stream:   true,
+		Options:  map[string]interface{}{},
+	}
+	if r.Options.Temperature != nil {
+		req.Options["temperature"] = *r.Options.Temperature
+	}
+	if r.Options.MaxTokens > 0 {
+		req.Options["num_predict"] = r.Options.MaxTokens
+	}
+	if len(r.Options.Stop) > 0 {
+		req.Options["stop"] = r.Options.Stop
 	}
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return nil, fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	resp, err := http.Post(
-		p.baseURL+"/api/generate",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	request, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errorResponse map[string]interface{}
 		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return fmt.Errorf("Ollama API error (status %d)", resp.StatusCode)
+			return nil, fmt.Errorf("Ollama API error (status %d)", resp.StatusCode)
 		}
-		return fmt.Errorf("Ollama API error: %v", errorResponse)
+		return nil, fmt.Errorf("Ollama API error: %v", errorResponse)
 	}
 
+	result := &Result{Model: p.model}
+
 	reader := bufio.NewReader(resp.Body)
 	for {
 		line, err := reader.ReadString('\n')
@@ -76,7 +95,7 @@ func (p *OllamaProvider) Stream(systemPrompt string, transcript string, callback
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading stream: %w", err)
+			return nil, fmt.Errorf("error reading stream: %w", err)
 		}
 
 		line = strings.TrimSpace(line)
@@ -86,17 +105,22 @@ func (p *OllamaProvider) Stream(systemPrompt string, transcript string, callback
 
 		var streamResp OllamaResponse
 		if err := json.Unmarshal([]byte(line), &streamResp); err != nil {
-			return fmt.Errorf("error parsing stream response: %w", err)
+			return nil, fmt.Errorf("error parsing stream response: %w", err)
 		}
 
-		if streamResp.Response != "" {
-			callback(streamResp.Response)
+		if streamResp.Message.Content != "" {
+			callback(streamResp.Message.Content)
 		}
 
 		if streamResp.Done {
+			result.StopReason = openAIStopReason(streamResp.DoneReason)
+			result.Usage = Usage{
+				InputTokens:  streamResp.PromptEvalCount,
+				OutputTokens: streamResp.EvalCount,
+			}
 			break
 		}
 	}
 
-	return nil
+	return result, nil
 }
@@ -3,6 +3,7 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,30 +12,32 @@ import (
 	"time"
 
 	"github.com/conormkelly/yts-cli/internal/config"
-	"github.com/zalando/go-keyring"
 )
 
 const (
-	openaiAPIURL         = "https://api.openai.com/v1/chat/completions"
-	openaiRetryBaseDelay = 1 * time.Second
+	openaiChatPath = "/chat/completions" // Appended to the configured base URL
 )
 
 type OpenAIProvider struct {
+	url         string
+	headers     map[string]string // Extra headers, e.g. for a gateway
 	model       string
 	apiKey      string
 	orgID       string
 	maxTokens   int
-	maxRetries  int
 	temperature float64
+	retry       retryPolicy
 	client      *http.Client
 }
 
 type OpenAIRequest struct {
-	Model       string          `json:"model"`
-	Messages    []OpenAIMessage `json:"messages"`
-	Stream      bool            `json:"stream"`
-	MaxTokens   int             `json:"max_tokens,omitempty"`
-	Temperature float64         `json:"temperature,omitempty"`
+	Model         string               `json:"model"`
+	Messages      []OpenAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream"`
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	Stop          []string             `json:"stop,omitempty"`
 }
 
 type OpenAIMessage struct {
@@ -42,6 +45,16 @@ type OpenAIMessage struct {
 	Content string `json:"content"`
 }
 
+// OpenAIStreamOptions asks for a final chunk carrying token usage
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 type OpenAIStreamResponse struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
@@ -52,6 +65,7 @@ type OpenAIStreamResponse struct {
 		Delta        Delta  `json:"delta"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *OpenAIUsage `json:"usage,omitempty"`
 }
 
 type Delta struct {
@@ -60,45 +74,66 @@ type Delta struct {
 }
 
 func NewOpenAIProvider(cfg *config.Config) (*OpenAIProvider, error) {
-	// Get API key from keyring
-	apiKey, err := keyring.Get(config.KeyringService, "openai")
+	apiKey, err := config.NewAPIKeyManager(cfg).GetAPIKey("openai")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get OpenAI API key from keyring: %w", err)
+		return nil, fmt.Errorf("failed to get OpenAI API key: %w", err)
 	}
+	return newOpenAIProvider(cfg, apiKey), nil
+}
 
+// newOpenAIProvider creates a provider that authenticates with apiKey
+func newOpenAIProvider(cfg *config.Config, apiKey string) *OpenAIProvider {
 	return &OpenAIProvider{
+		url:         strings.TrimSuffix(cfg.Providers.OpenAI.BaseURL, "/") + openaiChatPath,
+		headers:     cfg.Providers.OpenAI.Headers,
 		model:       cfg.Providers.OpenAI.Model,
 		apiKey:      apiKey,
 		orgID:       cfg.Providers.OpenAI.OrgID,
 		temperature: cfg.Providers.OpenAI.Temperature,
 		maxTokens:   cfg.Providers.OpenAI.MaxTokens,
-		maxRetries:  cfg.Providers.OpenAI.MaxRetries,
+		retry:       newRetryPolicy(cfg.Providers.OpenAI.MaxRetries),
 		client: &http.Client{
 			Timeout: time.Duration(cfg.Providers.OpenAI.TimeoutSecs) * time.Second,
 		},
-	}, nil
+	}
 }
 
-func (p *OpenAIProvider) Stream(systemPrompt string, transcript string, callback func(string)) error {
+func (p *OpenAIProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, p, systemPrompt, transcript, callback)
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
 	req := OpenAIRequest{
-		Model: p.model,
-		Messages: []OpenAIMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: transcript},
-		},
-		Stream:      true,
-		MaxTokens:   p.maxTokens,
-		Temperature: p.temperature,
+		Model:         p.model,
+		Messages:      openAIMessages(r),
+		Stream:        true,
+		StreamOptions: &OpenAIStreamOptions{IncludeUsage: true},
+		MaxTokens:     p.maxTokens,
+		Temperature:   &p.temperature,
+		Stop:          r.Options.Stop,
+	}
+	if r.Options.MaxTokens > 0 {
+		req.MaxTokens = r.Options.MaxTokens
+	}
+	if r.Options.Temperature != nil {
+		req.Temperature = r.Options.Temperature
 	}
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return fmt.Errorf("error marshaling request: %w", err)
+		return nil, fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	request, err := http.NewRequest("POST", openaiAPIURL, bytes.NewBuffer(jsonData))
+	return p.retry.generate(ctx, callback, func(callback func(string)) (*Result, error) {
+		return p.generateOnce(ctx, jsonData, callback)
+	})
+}
+
+// generateOnce makes a single streaming request to the Chat Completions API
+func (p *OpenAIProvider) generateOnce(ctx context.Context, jsonData []byte, callback func(string)) (*Result, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(jsonData))
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set headers
@@ -107,18 +142,20 @@ func (p *OpenAIProvider) Stream(systemPrompt string, transcript string, callback
 	if p.orgID != "" {
 		request.Header.Set("OpenAI-Organization", p.orgID)
 	}
+	setHeaders(request, p.headers)
 
 	resp, err := p.client.Do(request)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("OpenAI API error (%d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError("OpenAI", resp)
 	}
 
+	result := &Result{Model: p.model}
+
 	reader := bufio.NewReader(resp.Body)
 	for {
 		line, err := reader.ReadString('\n')
@@ -126,20 +163,27 @@ func (p *OpenAIProvider) Stream(systemPrompt string, transcript string, callback
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error reading stream: %w", err)
+			return nil, fmt.Errorf("error reading stream: %w", err)
 		}
 
-		line = strings.TrimSpace(line)
-		if line == "" || line == "data: [DONE]" {
+		data, ok := sseData(line)
+		if !ok {
 			continue
 		}
 
-		// Remove "data: " prefix
-		line = strings.TrimPrefix(line, "data: ")
-
 		var streamResp OpenAIStreamResponse
-		if err := json.Unmarshal([]byte(line), &streamResp); err != nil {
-			return fmt.Errorf("error parsing stream response: %w", err)
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return nil, fmt.Errorf("error parsing stream response: %w", err)
+		}
+
+		if streamResp.Model != "" {
+			result.Model = streamResp.Model
+		}
+		if streamResp.Usage != nil {
+			result.Usage = Usage{
+				InputTokens:  streamResp.Usage.PromptTokens,
+				OutputTokens: streamResp.Usage.CompletionTokens,
+			}
 		}
 
 		// Process choices
@@ -147,24 +191,33 @@ func (p *OpenAIProvider) Stream(systemPrompt string, transcript string, callback
 			if choice.Delta.Content != "" {
 				callback(choice.Delta.Content)
 			}
+			if choice.FinishReason != "" {
+				result.StopReason = openAIStopReason(choice.FinishReason)
+			}
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// Helper method to handle rate limits and retries
-func (p *OpenAIProvider) handleRetry(attempt int, err error) (bool, error) {
-	if attempt >= p.maxRetries {
-		return false, fmt.Errorf("max retries exceeded: %w", err)
+// openAIMessages converts a request to chat-completions messages, with the
+// system prompt as the first message
+func openAIMessages(r *Request) []OpenAIMessage {
+	messages := []OpenAIMessage{{Role: "system", Content: r.System}}
+	for _, m := range r.Messages {
+		messages = append(messages, OpenAIMessage{Role: m.Role, Content: m.Content})
 	}
+	return messages
+}
 
-	// Check if error is rate limit related
-	if strings.Contains(err.Error(), "rate limit") {
-		delay := time.Duration(1<<uint(attempt)) * openaiRetryBaseDelay
-		time.Sleep(delay)
-		return true, nil
+// openAIStopReason maps chat-completions finish reasons onto the common stop reasons
+func openAIStopReason(reason string) string {
+	switch reason {
+	case "stop":
+		return StopReasonEndTurn
+	case "length":
+		return StopReasonMaxTokens
+	default:
+		return reason
 	}
-
-	return false, err
 }
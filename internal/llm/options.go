@@ -0,0 +1,33 @@
+package llm
+
+import "context"
+
+// defaultsProvider fills in request options that callers leave unset
+type defaultsProvider struct {
+	provider Provider
+	defaults Options
+}
+
+// WithDefaults wraps p so requests use the options in defaults unless they
+// set their own
+func WithDefaults(p Provider, defaults Options) Provider {
+	return &defaultsProvider{provider: p, defaults: defaults}
+}
+
+func (d *defaultsProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	return streamAdapter(ctx, d, systemPrompt, transcript, callback)
+}
+
+func (d *defaultsProvider) Generate(ctx context.Context, r *Request, callback func(string)) (*Result, error) {
+	req := *r
+	if req.Options.Temperature == nil {
+		req.Options.Temperature = d.defaults.Temperature
+	}
+	if req.Options.MaxTokens == 0 {
+		req.Options.MaxTokens = d.defaults.MaxTokens
+	}
+	if req.Options.Stop == nil {
+		req.Options.Stop = d.defaults.Stop
+	}
+	return d.provider.Generate(ctx, &req, callback)
+}
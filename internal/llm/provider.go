@@ -2,26 +2,123 @@
 package llm
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/conormkelly/yts-cli/internal/config"
 )
 
+// Message roles used in conversations
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn in a conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Stop reasons reported in Result.StopReason. Providers' own values are
+// mapped onto these where they have an equivalent.
+const (
+	StopReasonEndTurn      = "end_turn"
+	StopReasonMaxTokens    = "max_tokens"
+	StopReasonStopSequence = "stop_sequence"
+)
+
+// Request is a single generation request
+type Request struct {
+	System   string
+	Messages []Message
+	Options  Options
+}
+
+// Options override the provider's configured settings for a single request.
+// Zero values leave the configured settings in place.
+type Options struct {
+	Temperature *float64
+	MaxTokens   int
+	Stop        []string
+}
+
+// Usage holds token counts, when the provider reports them
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Result describes a completed generation
+type Result struct {
+	Model      string `json:"model"`
+	StopReason string `json:"stop_reason"`
+	Usage      Usage  `json:"usage"`
+}
+
 type Provider interface {
-	Stream(systemPrompt string, transcript string, callback func(string)) error
+	// Generate runs a request, streaming generated text to callback
+	Generate(ctx context.Context, req *Request, callback func(string)) (*Result, error)
+	// Stream sends a single user message and streams the reply
+	Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error
+}
+
+// streamAdapter implements Stream on top of Generate
+func streamAdapter(ctx context.Context, p Provider, systemPrompt string, transcript string, callback func(string)) error {
+	_, err := p.Generate(ctx, &Request{
+		System:   systemPrompt,
+		Messages: []Message{{Role: RoleUser, Content: transcript}},
+	}, callback)
+	return err
+}
+
+// Float64 returns a pointer to v, for setting Options.Temperature
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// setHeaders adds configured static headers to a request, replacing any
+// standard header of the same name. An empty value removes the header, e.g.
+// to drop the API key when a gateway adds its own.
+func setHeaders(request *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		if value == "" {
+			request.Header.Del(name)
+		} else {
+			request.Header.Set(name, value)
+		}
+	}
+}
+
+// withTemperature applies a configured temperature to requests that don't
+// set their own
+func withTemperature(p Provider, temperature *float64) Provider {
+	if temperature == nil {
+		return p
+	}
+	return WithDefaults(p, Options{Temperature: temperature})
 }
 
 func NewProvider(cfg *config.Config) (Provider, error) {
 	switch cfg.Provider {
 	case "lmstudio":
-		return NewLMStudioProvider(cfg.Providers.LMStudio.BaseURL, cfg.Providers.LMStudio.Model), nil
+		provider := NewLMStudioProvider(cfg.Providers.LMStudio.BaseURL, cfg.Providers.LMStudio.Model)
+		return withTemperature(provider, cfg.Providers.LMStudio.Temperature), nil
 	case "ollama":
-		return NewOllamaProvider(cfg.Providers.Ollama.BaseURL, cfg.Providers.Ollama.Model), nil
+		provider := NewOllamaProvider(cfg.Providers.Ollama.BaseURL, cfg.Providers.Ollama.Model)
+		return withTemperature(provider, cfg.Providers.Ollama.Temperature), nil
 	case "claude":
 		return NewClaudeProvider(cfg)
 	case "openai":
 		return NewOpenAIProvider(cfg)
-	default:
-		return nil, fmt.Errorf("unsupported provider type: %s", cfg.Provider)
+	case "gemini":
+		return NewGeminiProvider(cfg)
+	}
+
+	if _, ok := cfg.Endpoint(cfg.Provider); ok {
+		return NewCompatibleProvider(cfg, cfg.Provider)
 	}
+	return nil, fmt.Errorf("unknown provider: %s (available: %s)", cfg.Provider, strings.Join(cfg.ProviderNames(), ", "))
 }
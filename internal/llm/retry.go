@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	retryBaseDelay     = 1 * time.Second
+	retryMaxDelay      = 30 * time.Second
+	retryAfterMaxDelay = 60 * time.Second
+)
+
+// APIError is returned when a provider API responds with an error status,
+// either in the HTTP response or as an error event in the stream
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the retry-after header, if present
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (%d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError from a failed HTTP response
+func newAPIError(provider string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+}
+
+// retryPolicy retries transient failures with exponential backoff. A request
+// is only retried while nothing has been streamed to the caller, so output is
+// never duplicated.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func newRetryPolicy(maxRetries int) retryPolicy {
+	return retryPolicy{
+		maxRetries: maxRetries,
+		baseDelay:  retryBaseDelay,
+		maxDelay:   retryMaxDelay,
+	}
+}
+
+// generate runs attempt until it succeeds, fails permanently, or runs out of
+// retries. attempt must send all generated text through the callback it is given.
+func (p retryPolicy) generate(ctx context.Context, callback func(string), attempt func(callback func(string)) (*Result, error)) (*Result, error) {
+	for n := 0; ; n++ {
+		streamed := false
+		result, err := attempt(func(text string) {
+			streamed = true
+			callback(text)
+		})
+		if err == nil {
+			return result, nil
+		}
+
+		if streamed || ctx.Err() != nil || !isRetryable(err) {
+			return nil, err
+		}
+		if n >= p.maxRetries {
+			if n > 0 {
+				return nil, fmt.Errorf("max retries exceeded: %w", err)
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(p.delay(n, err)):
+		}
+	}
+}
+
+// delay returns how long to wait before retry n (zero-based), preferring the
+// server's retry-after hint when one was sent
+func (p retryPolicy) delay(n int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, retryAfterMaxDelay)
+	}
+
+	delay := p.baseDelay << uint(n)
+	if delay <= 0 || delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	// Add up to 20% jitter so parallel clients don't retry in lockstep
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// isRetryable reports whether err is a transient failure: a timeout, rate
+// limit, overload or server error status, or a dropped connection
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode >= 500:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter reads retry-after-ms or retry-after (seconds or HTTP date)
+func parseRetryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+
+	value := header.Get("retry-after")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,264 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// reply is one scripted response from a flaky server
+type reply struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+const okStream = `data: {"choices":[{"delta":{"content":"Hello"},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+
+// newFlakyServer answers each request with the next reply, repeating the
+// last one once they run out, and counts the requests it receives
+func newFlakyServer(t *testing.T, replies ...reply) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		rep := replies[min(n, len(replies))-1]
+		for name, value := range rep.headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(rep.status)
+		fmt.Fprint(w, rep.body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// newRetryingProvider returns a provider for srv that retries up to
+// maxRetries times with a short backoff
+func newRetryingProvider(srv *httptest.Server, maxRetries int) *CompatibleProvider {
+	p := newCompatibleProvider("test", config.Endpoint{BaseURL: srv.URL + "/v1", MaxRetries: maxRetries}, "")
+	p.retry.baseDelay = time.Millisecond
+	p.retry.maxDelay = 5 * time.Millisecond
+	return p
+}
+
+func TestRetryStatuses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status       int
+		wantRequests int32
+	}{
+		{http.StatusRequestTimeout, 2},
+		{http.StatusTooManyRequests, 2},
+		{http.StatusInternalServerError, 2},
+		{http.StatusBadGateway, 2},
+		{http.StatusServiceUnavailable, 2},
+		{529, 2}, // Anthropic's "overloaded"
+		{http.StatusBadRequest, 1},
+		{http.StatusUnauthorized, 1},
+		{http.StatusForbidden, 1},
+		{http.StatusNotFound, 1},
+		{http.StatusUnprocessableEntity, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			t.Parallel()
+
+			srv, requests := newFlakyServer(t,
+				reply{status: tt.status, body: `{"error":"failed"}`},
+				reply{status: http.StatusOK, body: okStream},
+			)
+			var text strings.Builder
+			err := newRetryingProvider(srv, 3).Stream(context.Background(), "system", "hi", func(chunk string) {
+				text.WriteString(chunk)
+			})
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if tt.wantRequests > 1 {
+				if err != nil || text.String() != "Hello" {
+					t.Errorf("Stream() = %q, %v, want Hello after a retry", text.String(), err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("Stream() error = %v, want an APIError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		maxRetries int
+		wantErr    string
+	}{
+		{maxRetries: 0, wantErr: "test API error (503)"},
+		{maxRetries: 2, wantErr: "max retries exceeded: test API error (503)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.maxRetries), func(t *testing.T) {
+			t.Parallel()
+
+			srv, requests := newFlakyServer(t, reply{status: http.StatusServiceUnavailable, body: "unavailable"})
+			err := newRetryingProvider(srv, tt.maxRetries).Stream(context.Background(), "system", "hi", func(string) {})
+
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Stream() error = %v, want %q", err, tt.wantErr)
+			}
+			if got, want := requests.Load(), int32(tt.maxRetries+1); got != want {
+				t.Errorf("requests = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newFlakyServer(t,
+		reply{status: http.StatusTooManyRequests, headers: map[string]string{"retry-after-ms": "20"}},
+		reply{status: http.StatusOK, body: okStream},
+	)
+	provider := newRetryingProvider(srv, 1)
+	// Backing off without the hint would outlast the context
+	provider.retry.baseDelay = time.Minute
+	provider.retry.maxDelay = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := provider.Stream(ctx, "system", "hi", func(string) {}); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("retried after %v, want at least the 20ms the server asked for", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := newFlakyServer(t, reply{status: http.StatusTooManyRequests, headers: map[string]string{"retry-after": "30"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := newRetryingProvider(srv, 3).Stream(ctx, "system", "hi", func(string) {})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stream() error = %v, want the context's error while waiting to retry", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryAfterStreaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		body         string
+		wantRequests int32
+	}{
+		{
+			// Text already reached the caller, so retrying would repeat it
+			name:         "after output",
+			body:         `data: {"choices":[{"delta":{"content":"Hel"}}]}` + "\n\n",
+			wantRequests: 1,
+		},
+		{
+			name:         "before output",
+			body:         ": keep-alive\n\n",
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The first response promises more than it sends, so the
+			// connection drops mid-stream
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					w.Header().Set("Content-Length", "10000")
+					fmt.Fprint(w, tt.body)
+					return
+				}
+				fmt.Fprint(w, okStream)
+			}))
+			t.Cleanup(srv.Close)
+
+			var text strings.Builder
+			err := newRetryingProvider(srv, 3).Stream(context.Background(), "system", "hi", func(chunk string) {
+				text.WriteString(chunk)
+			})
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if tt.wantRequests == 1 {
+				if err == nil || text.String() != "Hel" {
+					t.Errorf("Stream() = %q, %v, want the partial output and an error", text.String(), err)
+				}
+			} else if err != nil || text.String() != "Hello" {
+				t.Errorf("Stream() = %q, %v, want Hello from the retry alone", text.String(), err)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{"none", nil, 0},
+		{"seconds", map[string]string{"Retry-After": "2"}, 2 * time.Second},
+		{"milliseconds win", map[string]string{"Retry-After": "2", "Retry-After-Ms": "250"}, 250 * time.Millisecond},
+		{"invalid", map[string]string{"Retry-After": "soon"}, 0},
+		{"negative", map[string]string{"Retry-After": "-1"}, 0},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		for name, value := range tt.headers {
+			header.Set(name, value)
+		}
+		if got := parseRetryAfter(header); got != tt.want {
+			t.Errorf("%s: parseRetryAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got := parseRetryAfter(header); got < 58*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(HTTP date a minute away) = %v", got)
+	}
+}
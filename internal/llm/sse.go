@@ -0,0 +1,18 @@
+package llm
+
+import "strings"
+
+// sseData returns the payload of a server-sent event data line. Comments such
+// as OpenRouter's ": OPENROUTER PROCESSING" keep-alives, other fields like
+// event: and id:, empty payloads and the [DONE] marker report false.
+func sseData(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "data:") {
+		return "", false
+	}
+	data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+	if data == "" || data == "[DONE]" {
+		return "", false
+	}
+	return data, true
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+func TestSSEData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{line: `data: {"a":1}` + "\n", want: `{"a":1}`, wantOK: true},
+		{line: `data:{"a":1}`, want: `{"a":1}`, wantOK: true},
+		{line: `data:   {"a":1}  `, want: `{"a":1}`, wantOK: true},
+		{line: "data: [DONE]"},
+		{line: "data:"},
+		{line: ""},
+		{line: ": keep-alive"},
+		{line: ": OPENROUTER PROCESSING"},
+		{line: "event: message"},
+		{line: "id: 1"},
+		{line: "retry: 1000"},
+	}
+
+	for _, tt := range tests {
+		got, ok := sseData(tt.line)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("sseData(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestOpenAIProviderStream(t *testing.T) {
+	t.Parallel()
+
+	// A gateway stream with keep-alive comments and named events
+	srv := newStreamServer(t, `: keep-alive
+
+event: message
+id: chatcmpl-1
+data: {"model":"served-model","choices":[{"delta":{"role":"assistant","content":"Hello"}}]}
+
+: keep-alive
+event: message
+data:{"choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2}}
+
+data: [DONE]
+`)
+	cfg := config.Default()
+	cfg.Providers.OpenAI.BaseURL = srv.URL + "/v1/"
+	provider := newOpenAIProvider(cfg, "sk-test")
+
+	var text strings.Builder
+	result, err := provider.Generate(context.Background(), &Request{
+		System:   "system",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	}, func(chunk string) {
+		text.WriteString(chunk)
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if text.String() != "Hello world" {
+		t.Errorf("text = %q, want Hello world", text.String())
+	}
+	if result.Model != "served-model" || result.StopReason != StopReasonEndTurn {
+		t.Errorf("Model = %q, StopReason = %q, want served-model and %q", result.Model, result.StopReason, StopReasonEndTurn)
+	}
+	if result.Usage.InputTokens != 12 || result.Usage.OutputTokens != 2 {
+		t.Errorf("Usage = %+v, want 12 in and 2 out", result.Usage)
+	}
+}
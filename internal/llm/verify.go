@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/conormkelly/yts-cli/internal/config"
+)
+
+// Kinds of API key check failure
+const (
+	KeyProblemAuth    = "auth"    // The key was rejected
+	KeyProblemQuota   = "quota"   // Rate limited or out of credit
+	KeyProblemModel   = "model"   // The model doesn't exist or the key can't use it
+	KeyProblemRequest = "request" // Anything else, such as a network error
+)
+
+// KeyCheckError is returned by CheckAPIKey, classifying why the check failed
+type KeyCheckError struct {
+	Kind string
+	Err  error
+}
+
+func (e *KeyCheckError) Error() string {
+	switch e.Kind {
+	case KeyProblemAuth:
+		return fmt.Sprintf("authentication failed: %v", e.Err)
+	case KeyProblemQuota:
+		return fmt.Sprintf("quota or rate limit exceeded: %v", e.Err)
+	case KeyProblemModel:
+		return fmt.Sprintf("model not available: %v", e.Err)
+	default:
+		return fmt.Sprintf("request failed: %v", e.Err)
+	}
+}
+
+func (e *KeyCheckError) Unwrap() error {
+	return e.Err
+}
+
+// CheckAPIKey makes the smallest possible authenticated request to a hosted
+// provider or configured endpoint with its configured model, without
+// retrying. An empty endpoint uses the provider's API URL.
+func CheckAPIKey(ctx context.Context, cfg *config.Config, provider string, apiKey string, endpoint string) error {
+	var p Provider
+	switch provider {
+	case "claude":
+		claude := newClaudeProvider(cfg, apiKey)
+		claude.retry = newRetryPolicy(0)
+		if endpoint != "" {
+			claude.url = endpoint
+		}
+		p = claude
+	case "openai":
+		openai := newOpenAIProvider(cfg, apiKey)
+		openai.retry = newRetryPolicy(0)
+		if endpoint != "" {
+			openai.url = endpoint
+		}
+		p = openai
+	case "gemini":
+		gemini := newGeminiProvider(cfg, apiKey)
+		gemini.retry = newRetryPolicy(0)
+		if endpoint != "" {
+			gemini.url = endpoint
+		}
+		p = gemini
+	default:
+		configured, ok := cfg.Endpoint(provider)
+		if !ok {
+			return fmt.Errorf("unknown provider: %s", provider)
+		}
+		compatible := newCompatibleProvider(provider, configured, apiKey)
+		compatible.retry = newRetryPolicy(0)
+		if endpoint != "" {
+			compatible.url = endpoint
+		}
+		p = compatible
+	}
+
+	_, err := p.Generate(ctx, &Request{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+		Options:  Options{MaxTokens: 1},
+	}, func(string) {})
+	if err != nil {
+		return &KeyCheckError{Kind: classifyKeyProblem(err), Err: err}
+	}
+	return nil
+}
+
+// classifyKeyProblem sorts API errors into auth, quota and model problems.
+// Providers use overlapping status codes, so the error body decides where
+// the code alone is ambiguous.
+func classifyKeyProblem(err error) string {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return KeyProblemRequest
+	}
+
+	body := strings.ToLower(apiErr.Body)
+	switch {
+	case strings.Contains(body, "api_key_invalid"):
+		// Gemini rejects keys with a 400
+		return KeyProblemAuth
+	case apiErr.StatusCode == http.StatusTooManyRequests,
+		apiErr.StatusCode == http.StatusPaymentRequired,
+		strings.Contains(body, "insufficient_quota"),
+		strings.Contains(body, "credit balance"):
+		return KeyProblemQuota
+	case apiErr.StatusCode == http.StatusNotFound,
+		strings.Contains(body, "model_not_found"),
+		(apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusForbidden) && strings.Contains(body, "model"):
+		return KeyProblemModel
+	case apiErr.StatusCode == http.StatusUnauthorized,
+		apiErr.StatusCode == http.StatusForbidden:
+		return KeyProblemAuth
+	default:
+		return KeyProblemRequest
+	}
+}
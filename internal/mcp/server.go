@@ -0,0 +1,358 @@
+// Package mcp implements a Model Context Protocol server over stdio.
+// Messages are newline-delimited JSON-RPC 2.0.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// protocolVersion is the newest MCP revision this server implements
+const protocolVersion = "2025-06-18"
+
+// supportedVersions lists the revisions a client may negotiate
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Tool describes a tool offered to the client
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// ToolHandler runs a tool with the raw arguments sent by the client and
+// returns its text output. Errors are reported to the client as tool
+// errors, so the model can see what went wrong.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Resource describes a resource offered to the client
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceProvider lists and reads resources
+type ResourceProvider interface {
+	List() ([]Resource, error)
+	Read(uri string) (mimeType string, text string, err error)
+}
+
+// Server is an MCP server. Register tools and resources before calling Serve.
+type Server struct {
+	name      string
+	version   string
+	tools     []Tool
+	handlers  map[string]ToolHandler
+	resources ResourceProvider
+
+	writeMu  sync.Mutex
+	out      io.Writer
+	cancelMu sync.Mutex
+	cancels  map[string]context.CancelFunc
+}
+
+// NewServer creates a server that identifies itself with name and version
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:     name,
+		version:  version,
+		handlers: map[string]ToolHandler{},
+		cancels:  map[string]context.CancelFunc{},
+	}
+}
+
+// AddTool registers a tool
+func (s *Server) AddTool(tool Tool, handler ToolHandler) {
+	s.tools = append(s.tools, tool)
+	s.handlers[tool.Name] = handler
+}
+
+// SetResources registers the provider for resources/list and resources/read
+func (s *Server) SetResources(p ResourceProvider) {
+	s.resources = p
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve reads requests from in and writes responses to out until in is
+// closed or ctx is cancelled. Requests are handled concurrently, so a
+// long-running tool call doesn't block pings or cancellations.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			return err
+		case line = <-lines:
+		}
+
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		// Notifications have no ID and get no response
+		if len(req.ID) == 0 {
+			s.handleNotification(req)
+			continue
+		}
+		if req.JSONRPC != "2.0" {
+			s.write(response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "jsonrpc must be \"2.0\""}})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleRequest(ctx, req)
+		}()
+	}
+}
+
+func (s *Server) handleNotification(req request) {
+	if req.Method != "notifications/cancelled" {
+		return
+	}
+
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	if cancel, ok := s.cancels[string(params.RequestID)]; ok {
+		cancel()
+	}
+}
+
+func (s *Server) handleRequest(ctx context.Context, req request) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancelMu.Lock()
+	s.cancels[string(req.ID)] = cancel
+	s.cancelMu.Unlock()
+	defer func() {
+		s.cancelMu.Lock()
+		delete(s.cancels, string(req.ID))
+		s.cancelMu.Unlock()
+		cancel()
+	}()
+
+	result, rpcErr := s.dispatch(ctx, req)
+
+	// Cancelled requests get no response
+	if ctx.Err() != nil {
+		return
+	}
+
+	resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+	if rpcErr == nil && result == nil {
+		resp.Result = struct{}{}
+	}
+	s.write(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	case "resources/list":
+		return s.listResources()
+	case "resources/read":
+		return s.readResource(req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+func (s *Server) initialize(raw json.RawMessage) (interface{}, *rpcError) {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	// Use the client's version if we support it, otherwise offer ours
+	version := protocolVersion
+	if supportedVersions[params.ProtocolVersion] {
+		version = params.ProtocolVersion
+	}
+
+	capabilities := map[string]interface{}{
+		"tools": map[string]interface{}{},
+	}
+	if s.resources != nil {
+		capabilities["resources"] = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    capabilities,
+		"serverInfo": map[string]string{
+			"name":    s.name,
+			"version": s.version,
+		},
+	}, nil
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) (interface{}, *rpcError) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	handler, ok := s.handlers[params.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+	if len(params.Arguments) == 0 {
+		params.Arguments = json.RawMessage("{}")
+	}
+
+	text, err := handler(ctx, params.Arguments)
+	if err != nil {
+		return map[string]interface{}{
+			"content": []textContent{{Type: "text", Text: err.Error()}},
+			"isError": true,
+		}, nil
+	}
+	return map[string]interface{}{
+		"content": []textContent{{Type: "text", Text: text}},
+	}, nil
+}
+
+func (s *Server) listResources() (interface{}, *rpcError) {
+	if s.resources == nil {
+		return map[string]interface{}{"resources": []Resource{}}, nil
+	}
+
+	resources, err := s.resources.List()
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	if resources == nil {
+		resources = []Resource{}
+	}
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func (s *Server) readResource(raw json.RawMessage) (interface{}, *rpcError) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if s.resources == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "resource not found: " + params.URI}
+	}
+
+	mimeType, text, err := s.resources.Read(params.URI)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return map[string]interface{}{
+		"contents": []map[string]string{{
+			"uri":      params.URI,
+			"mimeType": mimeType,
+			"text":     text,
+		}},
+	}, nil
+}
+
+// write sends one message. Writes are serialized so concurrent responses
+// don't interleave.
+func (s *Server) write(resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{
+			Code:    codeInternalError,
+			Message: fmt.Sprintf("failed to encode response: %v", err),
+		}})
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
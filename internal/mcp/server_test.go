@@ -0,0 +1,319 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// session drives a Server over in-memory pipes
+type session struct {
+	in        *io.PipeWriter
+	responses chan string
+	done      chan error
+}
+
+// startSession serves s until the session's input is closed
+func startSession(t *testing.T, s *Server) *session {
+	t.Helper()
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	sess := &session{in: inWriter, responses: make(chan string, 100), done: make(chan error, 1)}
+
+	go func() {
+		err := s.Serve(context.Background(), inReader, outWriter)
+		outWriter.Close()
+		sess.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			sess.responses <- scanner.Text()
+		}
+		close(sess.responses)
+	}()
+
+	t.Cleanup(func() { inWriter.Close() })
+	return sess
+}
+
+// send writes one line to the server
+func (s *session) send(t *testing.T, line string) {
+	t.Helper()
+	if _, err := io.WriteString(s.in, line+"\n"); err != nil {
+		t.Fatalf("failed to send %s: %v", line, err)
+	}
+}
+
+// receive returns the next response
+func (s *session) receive(t *testing.T) string {
+	t.Helper()
+	select {
+	case resp, ok := <-s.responses:
+		if !ok {
+			t.Fatal("server closed its output")
+		}
+		return resp
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a response")
+		return ""
+	}
+}
+
+// close ends the input and returns every response not yet received
+func (s *session) close(t *testing.T) []string {
+	t.Helper()
+	s.in.Close()
+	var rest []string
+	for resp := range s.responses {
+		rest = append(rest, resp)
+	}
+	if err := <-s.done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+	return rest
+}
+
+// assertJSON fails unless got and want hold the same JSON value
+func assertJSON(t *testing.T, got, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("response = %s\nwant       %s", got, want)
+	}
+}
+
+// stubResources serves a single resource
+type stubResources struct{}
+
+func (stubResources) List() ([]Resource, error) {
+	return []Resource{{URI: "yts://transcripts/abc/en", Name: "Test Video", MimeType: "text/plain"}}, nil
+}
+
+func (stubResources) Read(uri string) (string, string, error) {
+	if uri != "yts://transcripts/abc/en" {
+		return "", "", fmt.Errorf("resource not found: %s", uri)
+	}
+	return "text/plain", "Hello world", nil
+}
+
+// newTestServer returns a server with an echo tool, a failing tool and
+// stub resources
+func newTestServer() *Server {
+	s := NewServer("yts", "1.2.3")
+	s.AddTool(Tool{Name: "echo", Description: "Echo the text", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			var args struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(arguments, &args); err != nil {
+				return "", err
+			}
+			return args.Text, nil
+		})
+	s.AddTool(Tool{Name: "fail", Description: "Always fail", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			return "", errors.New("video not found")
+		})
+	s.SetResources(stubResources{})
+	return s
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		request  string
+		want     string
+		wantCode int // Checked instead of want when the message varies
+	}{
+		{
+			name:    "initialize",
+			request: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+			want:    `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26","capabilities":{"tools":{},"resources":{}},"serverInfo":{"name":"yts","version":"1.2.3"}}}`,
+		},
+		{
+			name:    "initialize with an unsupported version",
+			request: `{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+			want:    `{"jsonrpc":"2.0","id":"init","result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{},"resources":{}},"serverInfo":{"name":"yts","version":"1.2.3"}}}`,
+		},
+		{
+			name:    "ping",
+			request: `{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			want:    `{"jsonrpc":"2.0","id":2,"result":{}}`,
+		},
+		{
+			name:    "tools/list",
+			request: `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+			want: `{"jsonrpc":"2.0","id":3,"result":{"tools":[
+				{"name":"echo","description":"Echo the text","inputSchema":{"type":"object"}},
+				{"name":"fail","description":"Always fail","inputSchema":{"type":"object"}}]}}`,
+		},
+		{
+			name:    "tools/call",
+			request: `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hello"}}}`,
+			want:    `{"jsonrpc":"2.0","id":4,"result":{"content":[{"type":"text","text":"hello"}]}}`,
+		},
+		{
+			name:    "tools/call without arguments",
+			request: `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"echo"}}`,
+			want:    `{"jsonrpc":"2.0","id":5,"result":{"content":[{"type":"text","text":""}]}}`,
+		},
+		{
+			name:    "tool error",
+			request: `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"fail","arguments":{}}}`,
+			want:    `{"jsonrpc":"2.0","id":6,"result":{"content":[{"type":"text","text":"video not found"}],"isError":true}}`,
+		},
+		{
+			name:    "unknown tool",
+			request: `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"nope"}}`,
+			want:    `{"jsonrpc":"2.0","id":7,"error":{"code":-32602,"message":"unknown tool: nope"}}`,
+		},
+		{
+			name:     "invalid params",
+			request:  `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":["echo"]}`,
+			wantCode: codeInvalidParams,
+		},
+		{
+			name:    "resources/list",
+			request: `{"jsonrpc":"2.0","id":9,"method":"resources/list"}`,
+			want:    `{"jsonrpc":"2.0","id":9,"result":{"resources":[{"uri":"yts://transcripts/abc/en","name":"Test Video","mimeType":"text/plain"}]}}`,
+		},
+		{
+			name:    "resources/read",
+			request: `{"jsonrpc":"2.0","id":10,"method":"resources/read","params":{"uri":"yts://transcripts/abc/en"}}`,
+			want:    `{"jsonrpc":"2.0","id":10,"result":{"contents":[{"uri":"yts://transcripts/abc/en","mimeType":"text/plain","text":"Hello world"}]}}`,
+		},
+		{
+			name:    "unknown resource",
+			request: `{"jsonrpc":"2.0","id":11,"method":"resources/read","params":{"uri":"yts://transcripts/xyz/en"}}`,
+			want:    `{"jsonrpc":"2.0","id":11,"error":{"code":-32602,"message":"resource not found: yts://transcripts/xyz/en"}}`,
+		},
+		{
+			name:    "unknown method",
+			request: `{"jsonrpc":"2.0","id":12,"method":"prompts/list"}`,
+			want:    `{"jsonrpc":"2.0","id":12,"error":{"code":-32601,"message":"method not found: prompts/list"}}`,
+		},
+		{
+			name:    "malformed JSON",
+			request: `{"jsonrpc":"2.0","id":13,"method":`,
+			want:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"unexpected end of JSON input"}}`,
+		},
+		{
+			name:    "wrong JSON-RPC version",
+			request: `{"jsonrpc":"1.0","id":14,"method":"ping"}`,
+			want:    `{"jsonrpc":"2.0","id":14,"error":{"code":-32600,"message":"jsonrpc must be \"2.0\""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sess := startSession(t, newTestServer())
+			sess.send(t, tt.request)
+			if tt.wantCode != 0 {
+				var resp response
+				if err := json.Unmarshal([]byte(sess.receive(t)), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Errorf("error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+			} else {
+				assertJSON(t, sess.receive(t), tt.want)
+			}
+			if rest := sess.close(t); len(rest) > 0 {
+				t.Errorf("unexpected extra responses: %v", rest)
+			}
+		})
+	}
+}
+
+func TestServerNotifications(t *testing.T) {
+	t.Parallel()
+
+	sess := startSession(t, newTestServer())
+	sess.send(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	sess.send(t, "")
+	sess.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":99}}`)
+	sess.send(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+
+	// Only the ping is answered
+	assertJSON(t, sess.receive(t), `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	if rest := sess.close(t); len(rest) > 0 {
+		t.Errorf("notifications got responses: %v", rest)
+	}
+}
+
+func TestServerCancellation(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s := newTestServer()
+	s.AddTool(Tool{Name: "wait", InputSchema: json.RawMessage(`{}`)},
+		func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		})
+
+	sess := startSession(t, s)
+	sess.send(t, `{"jsonrpc":"2.0","id":"slow","method":"tools/call","params":{"name":"wait"}}`)
+	<-started
+
+	// Other requests are answered while the tool runs
+	sess.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"still here"}}}`)
+	assertJSON(t, sess.receive(t), `{"jsonrpc":"2.0","id":2,"result":{"content":[{"type":"text","text":"still here"}]}}`)
+
+	sess.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"slow","reason":"user pressed stop"}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not cancelled")
+	}
+
+	// The cancelled request gets no response
+	if rest := sess.close(t); len(rest) > 0 {
+		t.Errorf("cancelled request got a response: %v", rest)
+	}
+}
+
+func TestServerLargeMessage(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("x", 1024*1024)
+	sess := startSession(t, newTestServer())
+	sess.send(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"`+text+`"}}}`)
+
+	var resp struct {
+		Result struct {
+			Content []textContent `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(sess.receive(t)), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Result.Content) != 1 || resp.Result.Content[0].Text != text {
+		t.Error("large message was not echoed back intact")
+	}
+	sess.close(t)
+}
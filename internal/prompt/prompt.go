@@ -0,0 +1,308 @@
+// Package prompt renders configured prompts as Go text/template templates.
+//
+// Templates can use these fields of Data:
+//
+//	{{.Title}}       Video title
+//	{{.Query}}       The question being answered (query prompts)
+//	{{.Channel}}     Channel name
+//	{{.UploadDate}}  Upload date, YYYY-MM-DD
+//	{{.Duration}}    Video length, e.g. 12:34 or 1:02:03
+//	{{.Language}}    Language code of the transcript
+//	{{.Chapters}}    Chapters, each with .Title and .Start (e.g. 4:05)
+//	{{.URL}}         Video URL
+//	{{.Transcript}}  Transcript text (chat prompts)
+//
+// and these functions:
+//
+//	{{today}}                  Current date, YYYY-MM-DD
+//	{{now.Format "Jan 2"}}     Current time, for custom formats
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// Data is the data available to prompt templates
+type Data struct {
+	Title      string
+	Query      string
+	Channel    string
+	UploadDate string
+	Duration   string
+	Language   string
+	Chapters   []Chapter
+	URL        string
+	Transcript string
+}
+
+// Chapter is a chapter of the video
+type Chapter struct {
+	Title string
+	Start string
+}
+
+// NewData fills Data from a transcript. Query is left for the caller.
+func NewData(t *transcript.Transcript) Data {
+	data := Data{
+		Title:      t.Title,
+		Channel:    t.Channel,
+		UploadDate: t.UploadDate,
+		Language:   t.Language,
+		URL:        t.URL(),
+		Transcript: t.Text(),
+	}
+
+	duration := t.Duration
+	if duration == 0 && len(t.Entries) > 0 {
+		last := t.Entries[len(t.Entries)-1]
+		duration = last.Start + last.Duration
+	}
+	if duration > 0 {
+		data.Duration = FormatTimestamp(duration)
+	}
+
+	for _, c := range t.Chapters {
+		data.Chapters = append(data.Chapters, Chapter{Title: c.Title, Start: FormatTimestamp(c.Start)})
+	}
+	return data
+}
+
+// FormatTimestamp renders seconds as h:mm:ss or m:ss
+func FormatTimestamp(seconds float64) string {
+	total := int(seconds)
+	h, m, s := total/3600, (total%3600)/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+var funcs = template.FuncMap{
+	"today": func() string { return time.Now().Format(time.DateOnly) },
+	"now":   time.Now,
+}
+
+// Render executes text as a template with data. name identifies the prompt
+// in errors, e.g. "queries.system_prompt".
+func Render(name string, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", templateError(name, text, err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", templateError(name, text, err)
+	}
+	return out.String(), nil
+}
+
+// Validate checks that text is a valid template that only uses the fields
+// and functions available to prompts. The template isn't executed, so
+// prompts that need data only some videos have, such as
+// {{index .Chapters 1}}, are accepted.
+func Validate(name string, text string) error {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return templateError(name, text, err)
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		// Templates made with {{define}} can be called with any data
+		var dot reflect.Type
+		if t.Name() == name {
+			dot = dataType
+		}
+		c := &checker{name: name, tree: t.Tree}
+		if err := c.walk(t.Tree.Root, dot); err != nil {
+			return templateError(name, text, err)
+		}
+	}
+	return nil
+}
+
+var dataType = reflect.TypeOf(Data{})
+
+// checker checks the fields used in a parsed template against the types
+// they are used on. A nil type is unknown, e.g. the result of a function,
+// and isn't checked.
+type checker struct {
+	name string
+	tree *parse.Tree
+}
+
+func (c *checker) walk(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := c.walk(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, dot)
+	case *parse.WithNode:
+		t, err := c.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		return c.branch(&n.BranchNode, t, dot)
+	case *parse.RangeNode:
+		t, err := c.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			elem = t.Elem()
+		}
+		return c.branch(&n.BranchNode, elem, dot)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			_, err := c.pipe(n.Pipe, dot)
+			return err
+		}
+	}
+	return nil
+}
+
+// branch checks the pipeline of an if, with or range, then its body with
+// dot set to inner and its else branch with dot set to outer
+func (c *checker) branch(n *parse.BranchNode, inner, outer reflect.Type) error {
+	if _, err := c.pipe(n.Pipe, outer); err != nil {
+		return err
+	}
+	if err := c.walk(n.List, inner); err != nil {
+		return err
+	}
+	return c.walk(n.ElseList, outer)
+}
+
+// pipe checks a pipeline and returns its type, if known
+func (c *checker) pipe(p *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if p == nil {
+		return nil, nil
+	}
+	var result reflect.Type
+	for _, cmd := range p.Cmds {
+		result = nil
+		for _, arg := range cmd.Args {
+			t, err := c.arg(arg, dot)
+			if err != nil {
+				return nil, err
+			}
+			if len(cmd.Args) == 1 {
+				result = t
+			}
+		}
+	}
+	if len(p.Cmds) != 1 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// arg checks a command argument and returns its type, if known
+func (c *checker) arg(node parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.fields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		// $ is the data the template was called with
+		if n.Ident[0] == "$" {
+			return c.fields(n, dataType, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		t, err := c.arg(n.Node, dot)
+		if err != nil {
+			return nil, err
+		}
+		return c.fields(n, t, n.Field)
+	case *parse.PipeNode:
+		return c.pipe(n, dot)
+	}
+	return nil, nil
+}
+
+// fields resolves a chain of field or method names starting from t
+func (c *checker) fields(node parse.Node, t reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		if t == nil || t.Kind() == reflect.Interface {
+			return nil, nil
+		}
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if method, ok := reflect.PointerTo(t).MethodByName(name); ok {
+			t = nil
+			if method.Type.NumOut() > 0 {
+				t = method.Type.Out(0)
+			}
+			continue
+		}
+		if t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok && field.IsExported() {
+				t = field.Type
+				continue
+			}
+		}
+		if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+			t = t.Elem()
+			continue
+		}
+
+		location, context := c.tree.ErrorContext(node)
+		return nil, fmt.Errorf("template: %s: executing %q at <%s>: can't evaluate field %s in type %s", location, c.name, context, name, t)
+	}
+	return t, nil
+}
+
+// templateErrorPattern splits text/template errors into line and message,
+// e.g. `template: queries.system_prompt:3:12: executing ... at <.Foo>: ...`
+var templateErrorPattern = regexp.MustCompile(`^template: .*?:(\d+)(?::\d+)?: (?:executing "[^"]*" at )?(.*)$`)
+
+// legacyPlaceholderPattern matches the error for placeholders written
+// without the leading dot, as in older configs, e.g. {{title}}
+var legacyPlaceholderPattern = regexp.MustCompile(`^function "(title|query|transcript)" not defined$`)
+
+// templateError rewrites a text/template error to name the prompt and
+// quote the offending line
+func templateError(name string, text string, err error) error {
+	matches := templateErrorPattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return fmt.Errorf("invalid template in %s: %v", name, err)
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	msg := fmt.Sprintf("invalid template in %s, line %d: %s", name, line, matches[2])
+	if legacy := legacyPlaceholderPattern.FindStringSubmatch(matches[2]); legacy != nil {
+		field := strings.ToUpper(legacy[1][:1]) + legacy[1][1:]
+		msg += fmt.Sprintf(" (use {{.%s}} instead of {{%s}})", field, legacy[1])
+	}
+	if lines := strings.Split(text, "\n"); line >= 1 && line <= len(lines) {
+		msg += fmt.Sprintf("\n  %d | %s", line, lines[line-1])
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,139 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	for seconds, want := range map[float64]string{
+		0:      "0:00",
+		5.9:    "0:05",
+		65:     "1:05",
+		3599:   "59:59",
+		3600:   "1:00:00",
+		3723.5: "1:02:03",
+	} {
+		if got := FormatTimestamp(seconds); got != want {
+			t.Errorf("FormatTimestamp(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestNewData(t *testing.T) {
+	data := NewData(&transcript.Transcript{
+		VideoID:  "dQw4w9WgXcQ",
+		Title:    "Test Video",
+		Language: "en",
+		Entries: []transcript.TranscriptResponse{
+			{Text: "first", Start: 0, Duration: 2},
+			{Text: "last", Start: 70, Duration: 5},
+		},
+		VideoInfo: transcript.VideoInfo{
+			Channel:  "Test Channel",
+			Chapters: []transcript.Chapter{{Title: "Intro", Start: 0}, {Title: "Main", Start: 65}},
+		},
+	})
+
+	if data.Duration != "1:15" {
+		t.Errorf("Duration = %q, want 1:15 from the last entry", data.Duration)
+	}
+	if len(data.Chapters) != 2 || data.Chapters[1] != (Chapter{Title: "Main", Start: "1:05"}) {
+		t.Errorf("Chapters = %+v", data.Chapters)
+	}
+	if data.URL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" || data.Transcript != "first\nlast\n" {
+		t.Errorf("URL = %q, Transcript = %q", data.URL, data.Transcript)
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := Data{Title: "Test Video", Chapters: []Chapter{{Title: "Intro", Start: "0:00"}, {Title: "Main", Start: "1:05"}}}
+	got, err := Render("test", `{{.Title}}:{{range .Chapters}} {{.Start}} {{.Title}};{{end}}`, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "Test Video: 0:00 Intro; 1:05 Main;"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	if _, err := Render("test", "{{index .Chapters 5}}", data); err == nil {
+		t.Error("Render() of a missing chapter succeeded, want an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"Summarize the video.",
+		"{{.Title}} {{.Query}} {{.Channel}} {{.UploadDate}} {{.Duration}} {{.Language}} {{.URL}} {{.Transcript}}",
+		"{{index .Chapters 1}}",
+		"{{(index .Chapters 2).Title}}",
+		"{{range .Chapters}}{{.Start}} {{.Title}}\n{{end}}",
+		"{{range $i, $c := .Chapters}}{{$i}} {{$c.Title}} of {{$.Title}}{{end}}",
+		"{{with .Channel}}by {{.}}{{else}}{{.Title}}{{end}}",
+		"{{if .Chapters}}{{len .Chapters}} chapters{{else}}{{.Duration}}{{end}}",
+		"{{.Title | printf \"%q\"}}",
+		"{{today}} {{now.Format \"Jan 2\"}} {{now.Year}}",
+		`{{define "chapter"}}{{.Anything}}{{end}}{{range .Chapters}}{{template "chapter" .}}{{end}}`,
+	}
+	for _, text := range valid {
+		if err := Validate("summaries.short.system_prompt", text); err != nil {
+			t.Errorf("Validate(%q) error = %v", text, err)
+		}
+	}
+
+	invalid := []struct {
+		text string
+		want []string
+	}{
+		{
+			text: "Summary of\n{{.Tittle}}",
+			want: []string{"invalid template in summaries.short.system_prompt, line 2", "can't evaluate field Tittle", "2 | {{.Tittle}}"},
+		},
+		{
+			text: "{{title}}",
+			want: []string{`function "title" not defined`, "use {{.Title}} instead of {{title}}"},
+		},
+		{
+			text: "{{range .Chapters}}{{.Channel}}{{end}}",
+			want: []string{"can't evaluate field Channel in type prompt.Chapter"},
+		},
+		{
+			text: "{{with .Title}}{{.Length}}{{end}}",
+			want: []string{"can't evaluate field Length in type string"},
+		},
+		{
+			text: "{{.Chapters.Title}}",
+			want: []string{"can't evaluate field Title in type []prompt.Chapter"},
+		},
+		{
+			text: "{{range .Chapters}}{{$.Start}}{{end}}",
+			want: []string{"can't evaluate field Start in type prompt.Data"},
+		},
+		{
+			text: "{{if .Nope}}x{{end}}",
+			want: []string{"can't evaluate field Nope"},
+		},
+		{
+			text: "{{.Title",
+			want: []string{"invalid template in summaries.short.system_prompt, line 1"},
+		},
+		{
+			text: "{{shout .Title}}",
+			want: []string{`function "shout" not defined`},
+		},
+	}
+	for _, tt := range invalid {
+		err := Validate("summaries.short.system_prompt", tt.text)
+		if err == nil {
+			t.Errorf("Validate(%q) succeeded, want an error", tt.text)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate(%q) error = %q, want it to contain %q", tt.text, err, want)
+			}
+		}
+	}
+}
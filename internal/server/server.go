@@ -0,0 +1,285 @@
+// Package server exposes transcript fetching and summarization over HTTP
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+)
+
+var videoIDPattern = regexp.MustCompile(`^[0-9A-Za-z_-]{11}$`)
+
+// Server handles the HTTP API:
+//
+//	POST /summaries              Stream a summary as Server-Sent Events
+//	POST /queries                Stream an answer to a question as Server-Sent Events
+//	GET  /transcripts/{videoID}  Return the transcript as JSON
+type Server struct {
+	client *yts.Client
+	slots  chan struct{}
+}
+
+// New creates a server that runs at most maxConcurrent requests at once.
+// Requests over the limit are rejected with 503 Service Unavailable.
+func New(client *yts.Client, maxConcurrent int) *Server {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &Server{
+		client: client,
+		slots:  make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /summaries", s.limit(s.handleSummary))
+	mux.HandleFunc("POST /queries", s.limit(s.handleQuery))
+	mux.HandleFunc("GET /transcripts/{videoID}", s.limit(s.handleTranscript))
+	return mux
+}
+
+// FetchRequest holds the fields shared by every request body
+type FetchRequest struct {
+	URL         string   `json:"url"`
+	Languages   []string `json:"lang,omitempty"`
+	TranslateTo string   `json:"translate_to,omitempty"`
+}
+
+// SummaryRequest is the body of POST /summaries. Style takes precedence
+// over Long.
+type SummaryRequest struct {
+	FetchRequest
+	Style string `json:"style,omitempty"`
+	Long  bool   `json:"long,omitempty"`
+}
+
+// QueryRequest is the body of POST /queries
+type QueryRequest struct {
+	FetchRequest
+	Query string `json:"query"`
+}
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes an error. It is also the data of "error" events.
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// limit rejects requests once every concurrency slot is taken
+func (s *Server) limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+			next(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, "busy", "too many requests in progress, try again shortly")
+		}
+	}
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	var req SummaryRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	style := req.Style
+	if style == "" && req.Long {
+		style = "long"
+	}
+	if err := s.client.ValidateStyle(style); err != nil {
+		writeError(w, http.StatusBadRequest, "unknown_style", err.Error())
+		return
+	}
+
+	result, ok := s.fetch(w, r, req.FetchRequest)
+	if !ok {
+		return
+	}
+
+	s.stream(w, r, result, func(events *eventWriter) error {
+		_, err := s.client.SummarizeTranscript(r.Context(), result,
+			yts.Style(style),
+			yts.OnText(events.text),
+			yts.OnProgress(func(current, total int) {
+				events.send("progress", map[string]int{"section": current, "total": total})
+			}))
+		return err
+	})
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req QueryRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "query is required")
+		return
+	}
+
+	result, ok := s.fetch(w, r, req.FetchRequest)
+	if !ok {
+		return
+	}
+
+	s.stream(w, r, result, func(events *eventWriter) error {
+		_, err := s.client.AskTranscript(r.Context(), result, req.Query, yts.OnText(events.text))
+		return err
+	})
+}
+
+func (s *Server) handleTranscript(w http.ResponseWriter, r *http.Request) {
+	videoID := r.PathValue("videoID")
+	if !videoIDPattern.MatchString(videoID) {
+		writeError(w, http.StatusBadRequest, "invalid_video_id", "invalid video ID: "+videoID)
+		return
+	}
+
+	req := FetchRequest{
+		URL:         "https://www.youtube.com/watch?v=" + videoID,
+		TranslateTo: r.URL.Query().Get("translate_to"),
+	}
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		req.Languages = strings.Split(lang, ",")
+	}
+
+	result, ok := s.fetch(w, r, req)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// fetch fetches the transcript for a request, writing an error response
+// and returning false if it fails
+func (s *Server) fetch(w http.ResponseWriter, r *http.Request, req FetchRequest) (*yts.Transcript, bool) {
+	if req.URL == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "url is required")
+		return nil, false
+	}
+
+	result, err := s.client.Transcript(r.Context(), req.URL,
+		yts.Languages(req.Languages...),
+		yts.TranslateTo(req.TranslateTo))
+	if err != nil {
+		status, code := fetchErrorStatus(err)
+		writeError(w, status, code, err.Error())
+		return nil, false
+	}
+	return result, true
+}
+
+// fetchErrorStatus maps a fetch error to an HTTP status and error code
+func fetchErrorStatus(err error) (int, string) {
+	var (
+		disabled   *yts.ErrTranscriptsDisabled
+		notFound   *yts.ErrNoTranscriptFound
+		noLanguage *yts.ErrNoTranscriptInLanguage
+		playlist   *yts.ErrPlaylistURL
+		invalidURL *yts.ErrInvalidURL
+	)
+	switch {
+	case errors.As(err, &disabled):
+		return http.StatusForbidden, "transcripts_disabled"
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, "no_transcript"
+	case errors.As(err, &noLanguage):
+		return http.StatusNotFound, "no_transcript_in_language"
+	case errors.As(err, &playlist):
+		return http.StatusBadRequest, "playlist_url"
+	case errors.As(err, &invalidURL):
+		return http.StatusBadRequest, "invalid_url"
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, "cancelled"
+	default:
+		return http.StatusBadGateway, "fetch_failed"
+	}
+}
+
+// stream sends generated text as Server-Sent Events. A "meta" event
+// describing the video comes first, then "text" events as text is
+// generated, and finally "done" or "error".
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, result *yts.Transcript, generate func(events *eventWriter) error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming_unsupported", "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	events := &eventWriter{w: w, flusher: flusher}
+	events.send("meta", map[string]string{
+		"video_id": result.VideoID,
+		"title":    result.Title,
+		"language": result.Language,
+	})
+
+	if err := generate(events); err != nil {
+		if r.Context().Err() != nil {
+			return // Client went away
+		}
+		events.send("error", ErrorDetail{Code: "generation_failed", Message: err.Error()})
+		return
+	}
+	events.send("done", struct{}{})
+}
+
+// eventWriter writes Server-Sent Events
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (e *eventWriter) send(event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, payload)
+	e.flusher.Flush()
+}
+
+// text sends a chunk of generated text
+func (e *eventWriter) text(chunk string) {
+	e.send("text", map[string]string{"text": chunk})
+}
+
+// decodeRequest parses a JSON request body, writing an error response and
+// returning false if it is invalid
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: ErrorDetail{Code: code, Message: message}})
+}
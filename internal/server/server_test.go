@@ -0,0 +1,371 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+)
+
+// newYouTubeClient returns an HTTP client that sends every request to a stub
+// of YouTube. The stub's videos are:
+//
+//	okVideo0001  English captions
+//	disabled001  captions disabled
+//	nocaptions1  no caption tracks
+//	unplayable1  not playable
+func newYouTubeClient(t *testing.T) *http.Client {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/watch":
+			fmt.Fprint(w, `<html><head><title>Test Video - YouTube</title></head>
+<body><script>ytcfg.set({"INNERTUBE_API_KEY": "test-key"});</script></body></html>`)
+		case "/youtubei/v1/player":
+			var req struct {
+				VideoID string `json:"videoId"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			resp := map[string]interface{}{"playabilityStatus": map[string]string{"status": "OK"}}
+			switch req.VideoID {
+			case "disabled001":
+			case "nocaptions1":
+				resp["captions"] = map[string]interface{}{"playerCaptionsTracklistRenderer": map[string]interface{}{}}
+			case "unplayable1":
+				resp["playabilityStatus"] = map[string]string{"status": "LOGIN_REQUIRED", "reason": "Sign in to confirm your age"}
+			default:
+				resp["captions"] = map[string]interface{}{
+					"playerCaptionsTracklistRenderer": map[string]interface{}{
+						"captionTracks": []map[string]string{{
+							"baseUrl":      srv.URL + "/api/timedtext?v=" + req.VideoID,
+							"languageCode": "en",
+						}},
+					},
+				}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/api/timedtext":
+			fmt.Fprint(w, `<transcript><text start="0" dur="2">Hello</text><text start="2" dur="2">world</text></transcript>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubProvider streams reply word by word, or fails with err. When started
+// is set, it is closed as the first request arrives, and requests then wait
+// for release to be closed.
+type stubProvider struct {
+	reply   string
+	err     error
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *stubProvider) Generate(ctx context.Context, r *yts.Request, callback func(string)) (*yts.Result, error) {
+	if p.started != nil {
+		close(p.started)
+		<-p.release
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	for _, word := range strings.SplitAfter(p.reply, " ") {
+		callback(word)
+	}
+	return &yts.Result{}, nil
+}
+
+func (p *stubProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	_, err := p.Generate(ctx, &yts.Request{System: systemPrompt}, callback)
+	return err
+}
+
+// newTestServer starts the API with a stub provider and YouTube
+func newTestServer(t *testing.T, provider *stubProvider, maxConcurrent int) *httptest.Server {
+	t.Helper()
+	client, err := yts.New(yts.WithProvider(provider), yts.WithHTTPClient(newYouTubeClient(t)))
+	if err != nil {
+		t.Fatalf("yts.New() error = %v", err)
+	}
+	srv := httptest.NewServer(New(client, maxConcurrent).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// event is a parsed Server-Sent Event
+type event struct {
+	name string
+	data string
+}
+
+// readEvents parses a Server-Sent Events body
+func readEvents(t *testing.T, body io.Reader) []event {
+	t.Helper()
+	var events []event
+	var current event
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, current)
+			current = event{}
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		default:
+			t.Errorf("unexpected line in event stream: %q", line)
+		}
+	}
+	if current != (event{}) {
+		t.Errorf("event stream ended mid-event: %+v", current)
+	}
+	return events
+}
+
+func TestErrorResponses(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, &stubProvider{reply: "unused"}, 4)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"transcripts disabled", "GET", "/transcripts/disabled001", "", http.StatusForbidden, "transcripts_disabled"},
+		{"no transcript", "GET", "/transcripts/nocaptions1", "", http.StatusNotFound, "no_transcript"},
+		{"no transcript in language", "GET", "/transcripts/okVideo0001?lang=fr,de", "", http.StatusNotFound, "no_transcript_in_language"},
+		{"video not playable", "GET", "/transcripts/unplayable1", "", http.StatusBadGateway, "fetch_failed"},
+		{"invalid video ID", "GET", "/transcripts/short", "", http.StatusBadRequest, "invalid_video_id"},
+		{"playlist URL", "POST", "/summaries", `{"url": "https://www.youtube.com/playlist?list=PLtest"}`, http.StatusBadRequest, "playlist_url"},
+		{"invalid URL", "POST", "/summaries", `{"url": "not a video"}`, http.StatusBadRequest, "invalid_url"},
+		{"missing URL", "POST", "/summaries", `{}`, http.StatusBadRequest, "invalid_request"},
+		{"unknown field", "POST", "/summaries", `{"url": "https://youtu.be/okVideo0001", "stlye": "long"}`, http.StatusBadRequest, "invalid_request"},
+		{"malformed body", "POST", "/queries", `{"url":`, http.StatusBadRequest, "invalid_request"},
+		{"unknown style", "POST", "/summaries", `{"url": "https://youtu.be/okVideo0001", "style": "haiku"}`, http.StatusBadRequest, "unknown_style"},
+		{"missing query", "POST", "/queries", `{"url": "https://youtu.be/okVideo0001", "query": " "}`, http.StatusBadRequest, "invalid_request"},
+		{"fetch error on a query", "POST", "/queries", `{"url": "https://youtu.be/disabled001", "query": "Why?"}`, http.StatusForbidden, "transcripts_disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if body.Error.Code != tt.wantCode || body.Error.Message == "" {
+				t.Errorf("error = %+v, want code %s with a message", body.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	// Errors are matched however deeply they are wrapped
+	err := fmt.Errorf("failed to fetch: %w", &yts.ErrNoTranscriptFound{VideoID: "okVideo0001"})
+	if status, code := fetchErrorStatus(err); status != http.StatusNotFound || code != "no_transcript" {
+		t.Errorf("fetchErrorStatus(wrapped) = %d %s, want 404 no_transcript", status, code)
+	}
+	if status, code := fetchErrorStatus(context.Canceled); status != http.StatusServiceUnavailable || code != "cancelled" {
+		t.Errorf("fetchErrorStatus(context.Canceled) = %d %s, want 503 cancelled", status, code)
+	}
+}
+
+func TestGetTranscript(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, &stubProvider{}, 4)
+	resp, err := http.Get(srv.URL + "/transcripts/okVideo0001?lang=EN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var result yts.Transcript
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode transcript: %v", err)
+	}
+	if result.VideoID != "okVideo0001" || result.Title != "Test Video" || len(result.Entries) != 2 {
+		t.Errorf("transcript = %+v", result)
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		provider *stubProvider
+		want     []event
+	}{
+		{
+			name:     "summary",
+			path:     "/summaries",
+			body:     `{"url": "https://www.youtube.com/watch?v=okVideo0001", "long": true}`,
+			provider: &stubProvider{reply: "A test video."},
+			want: []event{
+				{"meta", `{"language":"en","title":"Test Video","video_id":"okVideo0001"}`},
+				{"text", `{"text":"A "}`},
+				{"text", `{"text":"test "}`},
+				{"text", `{"text":"video."}`},
+				{"done", `{}`},
+			},
+		},
+		{
+			name:     "query",
+			path:     "/queries",
+			body:     `{"url": "https://youtu.be/okVideo0001", "query": "What is said?"}`,
+			provider: &stubProvider{reply: "Hello world"},
+			want: []event{
+				{"meta", `{"language":"en","title":"Test Video","video_id":"okVideo0001"}`},
+				{"text", `{"text":"Hello "}`},
+				{"text", `{"text":"world"}`},
+				{"done", `{}`},
+			},
+		},
+		{
+			name:     "generation error",
+			path:     "/summaries",
+			body:     `{"url": "https://youtu.be/okVideo0001"}`,
+			provider: &stubProvider{err: errors.New("model crashed")},
+			want: []event{
+				{"meta", `{"language":"en","title":"Test Video","video_id":"okVideo0001"}`},
+				{"error", `{"code":"generation_failed","message":"model crashed"}`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t, tt.provider, 4)
+			resp, err := http.Post(srv.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", got)
+			}
+
+			got := readEvents(t, resp.Body)
+			if len(got) != len(tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	provider := &stubProvider{reply: "done", started: make(chan struct{}), release: make(chan struct{})}
+	srv := newTestServer(t, provider, 1)
+
+	// Hold the only slot with a summary that waits on the provider
+	first := make(chan error, 1)
+	go func() {
+		resp, err := http.Post(srv.URL+"/summaries", "application/json", strings.NewReader(`{"url": "https://youtu.be/okVideo0001"}`))
+		if err == nil {
+			_, err = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		first <- err
+	}()
+	<-provider.started
+
+	resp, err := http.Get(srv.URL + "/transcripts/okVideo0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 while the slot is taken", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("503 response has no Retry-After header")
+	}
+
+	close(provider.release)
+	if err := <-first; err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	// The slot is free again
+	resp, err = http.Get(srv.URL + "/transcripts/okVideo0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 once the slot is released", resp.StatusCode)
+	}
+}
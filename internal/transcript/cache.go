@@ -0,0 +1,55 @@
+package transcript
+
+import (
+	"strings"
+	"sync"
+)
+
+// Cache stores fetched transcripts so repeated requests for the same video
+// don't hit YouTube again. key identifies the language selection, see CacheKey.
+type Cache interface {
+	Get(videoID, key string) (*Transcript, bool)
+	Put(videoID, key string, t *Transcript) error
+}
+
+// CacheKey identifies the language selection of a fetch, so transcripts
+// fetched with different language preferences are cached separately
+func (o FetchOptions) CacheKey() string {
+	key := "auto"
+	if len(o.Languages) > 0 {
+		key = strings.ToLower(strings.Join(o.Languages, ","))
+	}
+	if o.TranslateTo != "" {
+		key += ">" + strings.ToLower(o.TranslateTo)
+	}
+	return key
+}
+
+// MemoryCache is an in-process Cache, mainly useful in tests
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]Transcript
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]Transcript)}
+}
+
+func (c *MemoryCache) Get(videoID, key string) (*Transcript, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.entries[videoID+"/"+key]
+	if !ok {
+		return nil, false
+	}
+	return &t, true
+}
+
+func (c *MemoryCache) Put(videoID, key string, t *Transcript) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[videoID+"/"+key] = *t
+	return nil
+}
@@ -0,0 +1,146 @@
+package transcript
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubYouTube answers the fetcher's requests in-process, counting them.
+// The video has manual English and German tracks, and the English track
+// can be translated.
+type stubYouTube struct {
+	requests atomic.Int32
+}
+
+func (s *stubYouTube) client() *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.requests.Add(1)
+
+		rec := httptest.NewRecorder()
+		switch r.URL.Path {
+		case "/watch":
+			fmt.Fprint(rec, `<title>Test Video - YouTube</title>"INNERTUBE_API_KEY": "test-key"`)
+		case "/youtubei/v1/player":
+			json.NewEncoder(rec).Encode(map[string]interface{}{
+				"playabilityStatus": map[string]string{"status": "OK"},
+				"captions": map[string]interface{}{
+					"playerCaptionsTracklistRenderer": map[string]interface{}{
+						"captionTracks": []map[string]interface{}{
+							{"baseUrl": "https://www.youtube.com/api/timedtext?lang=en", "languageCode": "en", "isTranslatable": true},
+							{"baseUrl": "https://www.youtube.com/api/timedtext?lang=de", "languageCode": "de"},
+						},
+					},
+				},
+			})
+		case "/api/timedtext":
+			lang := r.URL.Query().Get("lang")
+			if tlang := r.URL.Query().Get("tlang"); tlang != "" {
+				lang = tlang
+			}
+			fmt.Fprintf(rec, `<transcript><text start="0" dur="1">text in %s</text></transcript>`, lang)
+		default:
+			rec.WriteHeader(http.StatusNotFound)
+		}
+
+		resp := rec.Result()
+		resp.Request = r
+		return resp, nil
+	})}
+}
+
+func TestFetchCache(t *testing.T) {
+	t.Parallel()
+
+	youtube := &stubYouTube{}
+	fetcher := NewTranscriptFetcher(WithHTTPClient(youtube.client()), WithCache(NewMemoryCache()))
+	ctx := context.Background()
+	const url = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+
+	// fetch fetches with opts and reports whether YouTube was asked
+	fetch := func(opts FetchOptions) (*Transcript, bool) {
+		t.Helper()
+		before := youtube.requests.Load()
+		result, err := fetcher.Fetch(ctx, url, opts)
+		if err != nil {
+			t.Fatalf("Fetch(%+v) error = %v", opts, err)
+		}
+		return result, youtube.requests.Load() != before
+	}
+
+	tests := []struct {
+		name        string
+		opts        FetchOptions
+		wantFetched bool
+		wantText    string
+	}{
+		{name: "first fetch", opts: FetchOptions{}, wantFetched: true, wantText: "text in en\n"},
+		{name: "served from cache", opts: FetchOptions{}, wantFetched: false, wantText: "text in en\n"},
+		{name: "another language", opts: FetchOptions{Languages: []string{"de"}}, wantFetched: true, wantText: "text in de\n"},
+		{name: "that language from cache", opts: FetchOptions{Languages: []string{"DE"}}, wantFetched: false, wantText: "text in de\n"},
+		{name: "a translation", opts: FetchOptions{Languages: []string{"en"}, TranslateTo: "fr"}, wantFetched: true, wantText: "text in fr\n"},
+		{name: "the translation from cache", opts: FetchOptions{Languages: []string{"en"}, TranslateTo: "fr"}, wantFetched: false, wantText: "text in fr\n"},
+		{name: "the untranslated track is cached separately", opts: FetchOptions{Languages: []string{"en"}}, wantFetched: true, wantText: "text in en\n"},
+		{name: "refresh skips the cache", opts: FetchOptions{Refresh: true}, wantFetched: true, wantText: "text in en\n"},
+	}
+
+	for _, tt := range tests {
+		result, fetched := fetch(tt.opts)
+		if fetched != tt.wantFetched {
+			t.Errorf("%s: fetched from YouTube = %v, want %v", tt.name, fetched, tt.wantFetched)
+		}
+		if result.Text() != tt.wantText {
+			t.Errorf("%s: Text() = %q, want %q", tt.name, result.Text(), tt.wantText)
+		}
+	}
+}
+
+func TestFetchCacheReturnsCopies(t *testing.T) {
+	t.Parallel()
+
+	fetcher := NewTranscriptFetcher(WithHTTPClient((&stubYouTube{}).client()), WithCache(NewMemoryCache()))
+	first, err := fetcher.Fetch(context.Background(), "https://youtu.be/dQw4w9WgXcQ", FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Title = "changed by the caller"
+
+	second, err := fetcher.Fetch(context.Background(), "https://youtu.be/dQw4w9WgXcQ", FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Title != "Test Video" {
+		t.Errorf("cached Title = %q, want it unaffected by changes to an earlier result", second.Title)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opts FetchOptions
+		want string
+	}{
+		{FetchOptions{}, "auto"},
+		{FetchOptions{Refresh: true}, "auto"},
+		{FetchOptions{Languages: []string{"EN"}}, "en"},
+		{FetchOptions{Languages: []string{"de", "en"}}, "de,en"},
+		{FetchOptions{Languages: []string{"en", "de"}}, "en,de"},
+		{FetchOptions{TranslateTo: "FR"}, "auto>fr"},
+		{FetchOptions{Languages: []string{"ja"}, TranslateTo: "en"}, "ja>en"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.opts.CacheKey(); got != tt.want {
+			t.Errorf("CacheKey(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
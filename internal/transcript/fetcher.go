@@ -2,6 +2,7 @@ package transcript
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,6 +20,16 @@ import (
 // Custom error types
 type ErrTranscriptsDisabled struct{ VideoID string }
 type ErrNoTranscriptFound struct{ VideoID string }
+type ErrPlaylistURL struct{ PlaylistID string }
+type ErrInvalidURL struct{ URL string }
+
+// ErrNoTranscriptInLanguage is returned when a video has captions, but none
+// in any of the requested languages
+type ErrNoTranscriptInLanguage struct {
+	VideoID   string
+	Requested []string
+	Available []string
+}
 
 func (e ErrTranscriptsDisabled) Error() string {
 	return fmt.Sprintf("transcripts are disabled for video: %s", e.VideoID)
@@ -27,6 +39,19 @@ func (e ErrNoTranscriptFound) Error() string {
 	return fmt.Sprintf("no transcript found for video: %s", e.VideoID)
 }
 
+func (e ErrInvalidURL) Error() string {
+	return fmt.Sprintf("could not extract video ID from URL: %s", e.URL)
+}
+
+func (e ErrPlaylistURL) Error() string {
+	return fmt.Sprintf("URL points at playlist %s, not a single video", e.PlaylistID)
+}
+
+func (e ErrNoTranscriptInLanguage) Error() string {
+	return fmt.Sprintf("no transcript found for video %s in languages: %s (available: %s)",
+		e.VideoID, strings.Join(e.Requested, ", "), strings.Join(e.Available, ", "))
+}
+
 // InnerTubeContext represents the YouTube InnerTube API context
 type InnerTubeContext struct {
 	Client struct {
@@ -43,17 +68,18 @@ type InnerTubeRequest struct {
 
 // InnerTubeResponse represents the response from YouTube's InnerTube API
 type InnerTubeResponse struct {
-	Captions struct {
+	Captions *struct {
 		PlayerCaptionsTracklistRenderer struct {
 			CaptionTracks []struct {
-				BaseURL      string `json:"baseUrl"`
-				Name         struct {
+				BaseURL string `json:"baseUrl"`
+				Name    struct {
 					Runs []struct {
 						Text string `json:"text"`
 					} `json:"runs"`
 				} `json:"name"`
-				LanguageCode string `json:"languageCode"`
-				Kind         string `json:"kind,omitempty"`
+				LanguageCode   string `json:"languageCode"`
+				Kind           string `json:"kind,omitempty"`
+				IsTranslatable bool   `json:"isTranslatable"`
 			} `json:"captionTracks"`
 		} `json:"playerCaptionsTracklistRenderer"`
 	} `json:"captions"`
@@ -61,6 +87,11 @@ type InnerTubeResponse struct {
 		Status string `json:"status"`
 		Reason string `json:"reason,omitempty"`
 	} `json:"playabilityStatus"`
+	VideoDetails struct {
+		Author           string `json:"author"`
+		LengthSeconds    string `json:"lengthSeconds"`
+		ShortDescription string `json:"shortDescription"`
+	} `json:"videoDetails"`
 }
 
 // CaptionsData represents the YouTube captions JSON structure
@@ -82,20 +113,154 @@ type TranscriptResponse struct {
 	Duration float64 `json:"duration"`
 }
 
+// CaptionTrack describes a single caption track available for a video
+type CaptionTrack struct {
+	LanguageCode string `json:"language_code"`
+	Name         string `json:"name"`
+	Kind         string `json:"kind,omitempty"` // "asr" for auto-generated tracks
+	Translatable bool   `json:"translatable,omitempty"`
+	BaseURL      string `json:"-"`
+}
+
+// IsGenerated reports whether the track was auto-generated by speech recognition
+func (t CaptionTrack) IsGenerated() bool {
+	return t.Kind == "asr"
+}
+
+// FetchOptions controls which caption track Fetch selects
+type FetchOptions struct {
+	// Languages lists preferred language codes in priority order.
+	// When empty, the first manually created track is used.
+	Languages []string
+
+	// TranslateTo asks YouTube to machine-translate the selected track
+	// into this language when the track is in a different language
+	TranslateTo string
+
+	// Refresh skips cached transcripts, but still caches the fresh result
+	Refresh bool
+}
+
+// TranslatedByYouTube marks transcripts translated by YouTube's caption service
+const TranslatedByYouTube = "youtube"
+
+// Transcript is the result of a successful Fetch
+type Transcript struct {
+	VideoID string               `json:"video_id"`
+	Title   string               `json:"title"`
+	Track   CaptionTrack         `json:"track"`
+	Entries []TranscriptResponse `json:"entries"`
+
+	// Language is the language of Entries. It differs from Track.LanguageCode
+	// when the captions were translated.
+	Language     string `json:"language"`
+	TranslatedBy string `json:"translated_by,omitempty"`
+
+	VideoInfo
+}
+
+// VideoInfo holds the video details YouTube reports alongside the captions.
+// Fields are empty when YouTube doesn't provide them.
+type VideoInfo struct {
+	Channel    string    `json:"channel,omitempty"`
+	UploadDate string    `json:"upload_date,omitempty"` // YYYY-MM-DD
+	Duration   float64   `json:"duration,omitempty"`    // Seconds
+	Chapters   []Chapter `json:"chapters,omitempty"`
+}
+
+// Chapter is a section of a video, as listed in its description
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"` // Seconds
+}
+
+// URL returns the video's watch URL
+func (t *Transcript) URL() string {
+	return "https://www.youtube.com/watch?v=" + t.VideoID
+}
+
+// NeedsTranslation reports whether the transcript still has to be translated
+// to reach the given language
+func (t *Transcript) NeedsTranslation(lang string) bool {
+	return lang != "" && !sameLanguage(t.Language, lang)
+}
+
+// TranslationNote describes how the transcript was translated, e.g.
+// "ja → en (YouTube)", or returns "" if it wasn't
+func (t *Transcript) TranslationNote() string {
+	switch t.TranslatedBy {
+	case "":
+		return ""
+	case TranslatedByYouTube:
+		return fmt.Sprintf("%s → %s (YouTube)", t.Track.LanguageCode, t.Language)
+	default:
+		return fmt.Sprintf("%s → %s (%s)", t.Track.LanguageCode, t.Language, t.TranslatedBy)
+	}
+}
+
+// Text joins the transcript entries into plain text, one entry per line
+func (t *Transcript) Text() string {
+	var text strings.Builder
+	for _, entry := range t.Entries {
+		text.WriteString(entry.Text + "\n")
+	}
+	return text.String()
+}
+
+// TimestampedText is like Text, with each line prefixed by its start time
+func (t *Transcript) TimestampedText() string {
+	var text strings.Builder
+	for _, entry := range t.Entries {
+		text.WriteString(fmt.Sprintf("[%.1fs]: %s\n", entry.Start, entry.Text))
+	}
+	return text.String()
+}
+
+// sameLanguage compares the primary language subtags, so "en-GB" matches "en"
+func sameLanguage(a, b string) bool {
+	primary := func(code string) string {
+		return strings.SplitN(code, "-", 2)[0]
+	}
+	return strings.EqualFold(primary(a), primary(b))
+}
+
 // TranscriptFetcher handles fetching transcripts from YouTube
 type TranscriptFetcher struct {
 	httpClient *http.Client
+	cache      Cache
+}
+
+// Option configures a TranscriptFetcher
+type Option func(*TranscriptFetcher)
+
+// WithCache makes Fetch read and store transcripts in c
+func WithCache(c Cache) Option {
+	return func(f *TranscriptFetcher) {
+		f.cache = c
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *TranscriptFetcher) {
+		f.httpClient = client
+	}
 }
 
-func NewTranscriptFetcher() *TranscriptFetcher {
-	return &TranscriptFetcher{
+func NewTranscriptFetcher(opts ...Option) *TranscriptFetcher {
+	f := &TranscriptFetcher{
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
-func extractVideoID(url string) (string, error) {
+// ExtractVideoID returns the 11-character video ID from a YouTube URL
+func ExtractVideoID(url string) (string, error) {
 	patterns := []string{
 		`(?:v=|\/)([0-9A-Za-z_-]{11}).*`,
 		`(?:youtu\.be\/)([0-9A-Za-z_-]{11})`,
@@ -108,21 +273,104 @@ func extractVideoID(url string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("could not extract video ID from URL: %s", url)
+	return "", &ErrInvalidURL{URL: url}
 }
 
-func (f *TranscriptFetcher) Fetch(videoURL string) (string, []TranscriptResponse, error) {
+// Fetch retrieves the transcript for a video, picking the caption track
+// that best matches opts.Languages. Manually created tracks are preferred
+// over auto-generated ones in the same language.
+func (f *TranscriptFetcher) Fetch(ctx context.Context, videoURL string, opts FetchOptions) (*Transcript, error) {
 	// 1. Extract video ID
-	videoID, err := extractVideoID(videoURL)
+	videoID, err := ExtractVideoID(videoURL)
+	if err != nil {
+		if playlistID, ok := ExtractPlaylistID(videoURL); ok {
+			return nil, &ErrPlaylistURL{PlaylistID: playlistID}
+		}
+		return nil, fmt.Errorf("invalid video ID: %w", err)
+	}
+
+	// 2. Serve from the cache when possible
+	cacheKey := opts.CacheKey()
+	if f.cache != nil && !opts.Refresh {
+		if cached, ok := f.cache.Get(videoID, cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	// 3. Look up title, video details and available caption tracks
+	title, info, tracks, err := f.fetchCaptionTracks(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Pick the track matching the language preferences. Without explicit
+	// preferences, a track already in the translation target is best.
+	var track CaptionTrack
+	if len(opts.Languages) == 0 && opts.TranslateTo != "" {
+		track, err = selectTrack(videoID, tracks, []string{opts.TranslateTo})
+	}
+	if track.BaseURL == "" {
+		track, err = selectTrack(videoID, tracks, opts.Languages)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Fetch and parse transcript
+	// Remove &fmt=srv3 from the URL as the Python library does
+	baseURL := strings.Replace(track.BaseURL, "&fmt=srv3", "", 1)
+	entries, err := f.fetchTranscriptFromURL(ctx, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %w", err)
+	}
+
+	result := &Transcript{
+		VideoID:   videoID,
+		Title:     title,
+		Track:     track,
+		Entries:   entries,
+		Language:  track.LanguageCode,
+		VideoInfo: info,
+	}
+
+	// 6. Ask YouTube for a machine translation if needed. When YouTube refuses,
+	// the original transcript is returned and NeedsTranslation stays true.
+	if result.NeedsTranslation(opts.TranslateTo) && track.Translatable {
+		translated, err := f.fetchTranscriptFromURL(ctx, baseURL+"&tlang="+url.QueryEscape(opts.TranslateTo))
+		if err == nil && len(translated) > 0 {
+			result.Entries = translated
+			result.Language = opts.TranslateTo
+			result.TranslatedBy = TranslatedByYouTube
+		}
+	}
+
+	// Caching is best-effort; a failed write shouldn't fail the fetch
+	if f.cache != nil {
+		_ = f.cache.Put(videoID, cacheKey, result)
+	}
+
+	return result, nil
+}
+
+// ListTracks returns the video title and every caption track available for it
+func (f *TranscriptFetcher) ListTracks(ctx context.Context, videoURL string) (string, []CaptionTrack, error) {
+	videoID, err := ExtractVideoID(videoURL)
 	if err != nil {
 		return "", nil, fmt.Errorf("invalid video ID: %w", err)
 	}
 
-	// 2. Fetch video page to get title and API key
+	title, _, tracks, err := f.fetchCaptionTracks(ctx, videoID)
+	return title, tracks, err
+}
+
+// fetchCaptionTracks scrapes the watch page for the title and InnerTube API key,
+// then asks InnerTube for the video details and list of caption tracks
+func (f *TranscriptFetcher) fetchCaptionTracks(ctx context.Context, videoID string) (string, VideoInfo, []CaptionTrack, error) {
+	// Fetch video page to get title and API key
 	watchURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
-	req, err := http.NewRequest("GET", watchURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", watchURL, nil)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to create request: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers to mimic browser request
@@ -131,14 +379,14 @@ func (f *TranscriptFetcher) Fetch(videoURL string) (string, []TranscriptResponse
 
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch video page: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to fetch video page: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// 3. Read and parse HTML
+	// Read and parse HTML
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to read response body: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	htmlBody := string(body)
@@ -146,41 +394,106 @@ func (f *TranscriptFetcher) Fetch(videoURL string) (string, []TranscriptResponse
 	// Extract title
 	title, err := extractTitle(htmlBody)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to extract video title: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to extract video title: %w", err)
 	}
 
 	// Extract InnerTube API key
 	apiKey, err := extractInnerTubeAPIKey(htmlBody, videoID)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to extract API key: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to extract API key: %w", err)
 	}
 
-	// 4. Fetch captions using InnerTube API
-	innerTubeResp, err := f.fetchInnerTubeData(videoID, apiKey)
+	// Fetch captions using InnerTube API
+	innerTubeResp, err := f.fetchInnerTubeData(ctx, videoID, apiKey)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch InnerTube data: %w", err)
+		return "", VideoInfo{}, nil, fmt.Errorf("failed to fetch InnerTube data: %w", err)
 	}
 
 	// Check playability status
 	if innerTubeResp.PlayabilityStatus.Status != "OK" {
-		return "", nil, fmt.Errorf("video is not playable: %s - %s", innerTubeResp.PlayabilityStatus.Status, innerTubeResp.PlayabilityStatus.Reason)
+		return "", VideoInfo{}, nil, fmt.Errorf("video is not playable: %s - %s", innerTubeResp.PlayabilityStatus.Status, innerTubeResp.PlayabilityStatus.Reason)
+	}
+
+	// No captions renderer at all means the uploader disabled captions
+	if innerTubeResp.Captions == nil {
+		return "", VideoInfo{}, nil, &ErrTranscriptsDisabled{VideoID: videoID}
 	}
 
 	// Extract caption tracks
 	captionTracks := innerTubeResp.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks
 	if len(captionTracks) == 0 {
-		return "", nil, &ErrNoTranscriptFound{VideoID: videoID}
+		return "", VideoInfo{}, nil, &ErrNoTranscriptFound{VideoID: videoID}
 	}
 
-	// 5. Fetch and parse transcript (using first available track)
-	// Remove &fmt=srv3 from the URL as the Python library does
-	baseURL := strings.Replace(captionTracks[0].BaseURL, "&fmt=srv3", "", 1)
-	transcript, err := f.fetchTranscriptFromURL(baseURL)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch transcript: %w", err)
+	tracks := make([]CaptionTrack, 0, len(captionTracks))
+	for _, ct := range captionTracks {
+		var name strings.Builder
+		for _, run := range ct.Name.Runs {
+			name.WriteString(run.Text)
+		}
+		tracks = append(tracks, CaptionTrack{
+			LanguageCode: ct.LanguageCode,
+			Name:         name.String(),
+			Kind:         ct.Kind,
+			Translatable: ct.IsTranslatable,
+			BaseURL:      ct.BaseURL,
+		})
 	}
 
-	return title, transcript, nil
+	info := VideoInfo{
+		Channel:    innerTubeResp.VideoDetails.Author,
+		UploadDate: extractUploadDate(htmlBody),
+		Chapters:   parseChapters(innerTubeResp.VideoDetails.ShortDescription),
+	}
+	info.Duration, _ = strconv.ParseFloat(innerTubeResp.VideoDetails.LengthSeconds, 64)
+
+	return title, info, tracks, nil
+}
+
+// selectTrack picks the best track for the preferred languages. Languages are
+// tried in order; within a language, manual tracks win over auto-generated ones.
+func selectTrack(videoID string, tracks []CaptionTrack, languages []string) (CaptionTrack, error) {
+	if len(languages) == 0 {
+		for _, track := range tracks {
+			if !track.IsGenerated() {
+				return track, nil
+			}
+		}
+		return tracks[0], nil
+	}
+
+	for _, lang := range languages {
+		var generated *CaptionTrack
+		for i := range tracks {
+			if !strings.EqualFold(tracks[i].LanguageCode, lang) {
+				continue
+			}
+			if !tracks[i].IsGenerated() {
+				return tracks[i], nil
+			}
+			if generated == nil {
+				generated = &tracks[i]
+			}
+		}
+		if generated != nil {
+			return *generated, nil
+		}
+	}
+
+	available := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		code := track.LanguageCode
+		if track.IsGenerated() {
+			code += " (auto-generated)"
+		}
+		available = append(available, code)
+	}
+
+	return CaptionTrack{}, &ErrNoTranscriptInLanguage{
+		VideoID:   videoID,
+		Requested: languages,
+		Available: available,
+	}
 }
 
 func extractTitle(htmlText string) (string, error) {
@@ -193,6 +506,43 @@ func extractTitle(htmlText string) (string, error) {
 	return html.UnescapeString(rawTitle), nil
 }
 
+var uploadDatePattern = regexp.MustCompile(`"(?:uploadDate|publishDate)":"(\d{4}-\d{2}-\d{2})`)
+
+// extractUploadDate finds the upload date in the watch page's embedded
+// microformat data
+func extractUploadDate(htmlText string) string {
+	if matches := uploadDatePattern.FindStringSubmatch(htmlText); len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+var chapterPattern = regexp.MustCompile(`^\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*[-–—:|]?\s*(.+)$`)
+
+// parseChapters reads chapters from a video description. Like YouTube, it
+// only accepts a list of at least three timestamps starting at 0:00.
+func parseChapters(description string) []Chapter {
+	var chapters []Chapter
+	for _, line := range strings.Split(description, "\n") {
+		matches := chapterPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		var start float64
+		for _, part := range strings.Split(matches[1], ":") {
+			n, _ := strconv.Atoi(part)
+			start = start*60 + float64(n)
+		}
+		chapters = append(chapters, Chapter{Title: strings.TrimSpace(matches[2]), Start: start})
+	}
+
+	if len(chapters) < 3 || chapters[0].Start != 0 {
+		return nil
+	}
+	return chapters
+}
+
 func extractInnerTubeAPIKey(html string, videoID string) (string, error) {
 	// Pattern to extract the InnerTube API key from the HTML
 	pattern := `"INNERTUBE_API_KEY":\s*"([a-zA-Z0-9_-]+)"`
@@ -211,7 +561,7 @@ func extractInnerTubeAPIKey(html string, videoID string) (string, error) {
 	return "", fmt.Errorf("could not extract InnerTube API key for video: %s", videoID)
 }
 
-func (f *TranscriptFetcher) fetchInnerTubeData(videoID string, apiKey string) (*InnerTubeResponse, error) {
+func (f *TranscriptFetcher) fetchInnerTubeData(ctx context.Context, videoID string, apiKey string) (*InnerTubeResponse, error) {
 	// Create InnerTube API request
 	innerTubeReq := InnerTubeRequest{
 		Context: InnerTubeContext{
@@ -233,7 +583,7 @@ func (f *TranscriptFetcher) fetchInnerTubeData(videoID string, apiKey string) (*
 
 	// Make POST request to InnerTube API
 	url := fmt.Sprintf("https://www.youtube.com/youtubei/v1/player?key=%s", apiKey)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create InnerTube request: %w", err)
 	}
@@ -260,8 +610,13 @@ func (f *TranscriptFetcher) fetchInnerTubeData(videoID string, apiKey string) (*
 	return &innerTubeResp, nil
 }
 
-func (f *TranscriptFetcher) fetchTranscriptFromURL(url string) ([]TranscriptResponse, error) {
-	resp, err := f.httpClient.Get(url)
+func (f *TranscriptFetcher) fetchTranscriptFromURL(ctx context.Context, url string) ([]TranscriptResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+package transcript
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectTrack(t *testing.T) {
+	t.Parallel()
+
+	// Tracks are identified in the tests by their BaseURL
+	tracks := []CaptionTrack{
+		{LanguageCode: "en", Kind: "asr", BaseURL: "en-asr"},
+		{LanguageCode: "de", BaseURL: "de"},
+		{LanguageCode: "en", BaseURL: "en"},
+		{LanguageCode: "pt-BR", BaseURL: "pt-BR"},
+		{LanguageCode: "ja", Kind: "asr", BaseURL: "ja-asr"},
+	}
+
+	tests := []struct {
+		name      string
+		tracks    []CaptionTrack
+		languages []string
+		want      string
+	}{
+		{name: "no preference picks the first manual track", tracks: tracks, want: "de"},
+		{name: "no preference and only generated tracks", tracks: tracks[4:], want: "ja-asr"},
+		{name: "manual track wins over an earlier generated one", tracks: tracks, languages: []string{"en"}, want: "en"},
+		{name: "generated track when there is no manual one", tracks: tracks, languages: []string{"ja"}, want: "ja-asr"},
+		{name: "languages are tried in order", tracks: tracks, languages: []string{"fr", "ja", "en"}, want: "ja-asr"},
+		{name: "codes match case-insensitively", tracks: tracks, languages: []string{"EN"}, want: "en"},
+		{name: "regional codes match case-insensitively", tracks: tracks, languages: []string{"pt-br"}, want: "pt-BR"},
+	}
+
+	for _, tt := range tests {
+		got, err := selectTrack("dQw4w9WgXcQ", tt.tracks, tt.languages)
+		if err != nil {
+			t.Errorf("%s: selectTrack() error = %v", tt.name, err)
+			continue
+		}
+		if got.BaseURL != tt.want {
+			t.Errorf("%s: selectTrack() = %s, want %s", tt.name, got.BaseURL, tt.want)
+		}
+	}
+}
+
+func TestSelectTrackNoLanguage(t *testing.T) {
+	t.Parallel()
+
+	tracks := []CaptionTrack{
+		{LanguageCode: "en", Kind: "asr"},
+		{LanguageCode: "de"},
+	}
+	_, err := selectTrack("dQw4w9WgXcQ", tracks, []string{"fr", "es"})
+
+	var noLanguage *ErrNoTranscriptInLanguage
+	if !errors.As(err, &noLanguage) {
+		t.Fatalf("selectTrack() error = %v, want ErrNoTranscriptInLanguage", err)
+	}
+	if want := []string{"fr", "es"}; !reflect.DeepEqual(noLanguage.Requested, want) {
+		t.Errorf("Requested = %v, want %v", noLanguage.Requested, want)
+	}
+	if want := []string{"en (auto-generated)", "de"}; !reflect.DeepEqual(noLanguage.Available, want) {
+		t.Errorf("Available = %v, want %v", noLanguage.Available, want)
+	}
+	if want := "no transcript found for video dQw4w9WgXcQ in languages: fr, es (available: en (auto-generated), de)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNeedsTranslation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		language string
+		target   string
+		want     bool
+	}{
+		{"en", "", false},
+		{"en", "en", false},
+		{"en-GB", "EN", false},
+		{"en", "de", true},
+		{"pt-BR", "pt", false},
+	}
+
+	for _, tt := range tests {
+		transcript := &Transcript{Language: tt.language}
+		if got := transcript.NeedsTranslation(tt.target); got != tt.want {
+			t.Errorf("NeedsTranslation(%q) for %q = %v, want %v", tt.target, tt.language, got, tt.want)
+		}
+	}
+}
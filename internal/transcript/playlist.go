@@ -0,0 +1,234 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxPlaylistPages bounds how many continuation pages are followed, so a
+// malformed response can't loop forever. Each page holds up to 100 videos.
+const maxPlaylistPages = 100
+
+// Playlist is the result of a successful FetchPlaylist
+type Playlist struct {
+	ID     string          `json:"id"`
+	Title  string          `json:"title"`
+	Videos []PlaylistVideo `json:"videos"`
+}
+
+// PlaylistVideo is a single entry in a playlist
+type PlaylistVideo struct {
+	VideoID string `json:"video_id"`
+	Title   string `json:"title"`
+}
+
+// URL returns the watch URL for the video
+func (v PlaylistVideo) URL() string {
+	return "https://www.youtube.com/watch?v=" + v.VideoID
+}
+
+// ExtractPlaylistID returns the playlist ID from the list= parameter of a
+// YouTube URL, and whether there was one
+func ExtractPlaylistID(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	id := u.Query().Get("list")
+	return id, id != ""
+}
+
+// IsPlaylistURL reports whether the URL points at a playlist rather than a
+// single video
+func IsPlaylistURL(rawURL string) bool {
+	if _, ok := ExtractPlaylistID(rawURL); !ok {
+		return false
+	}
+	_, err := ExtractVideoID(rawURL)
+	return err != nil
+}
+
+// FetchPlaylist lists the videos in a playlist. Regular playlists are read
+// from the InnerTube browse endpoint, following continuation tokens until
+// every page has been read. Auto-generated mixes can't be browsed, so for
+// those the next endpoint's playlist panel is used instead.
+func (f *TranscriptFetcher) FetchPlaylist(ctx context.Context, playlistURL string) (*Playlist, error) {
+	playlistID, ok := ExtractPlaylistID(playlistURL)
+	if !ok {
+		return nil, fmt.Errorf("no playlist ID in URL: %s", playlistURL)
+	}
+
+	playlist := &Playlist{ID: playlistID}
+	seen := map[string]bool{}
+	add := func(videos []PlaylistVideo) {
+		for _, v := range videos {
+			if !seen[v.VideoID] {
+				seen[v.VideoID] = true
+				playlist.Videos = append(playlist.Videos, v)
+			}
+		}
+	}
+
+	body := map[string]interface{}{"browseId": "VL" + playlistID}
+	for page := 0; page < maxPlaylistPages; page++ {
+		data, err := f.innerTubeWeb(ctx, "browse", body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch playlist: %w", err)
+		}
+
+		if playlist.Title == "" {
+			playlist.Title = playlistTitle(data)
+		}
+		videos, token := parsePlaylistPage(data, "playlistVideoRenderer")
+		add(videos)
+
+		if token == "" {
+			break
+		}
+		body = map[string]interface{}{"continuation": token}
+	}
+
+	if len(playlist.Videos) == 0 {
+		data, err := f.innerTubeWeb(ctx, "next", map[string]interface{}{"playlistId": playlistID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch playlist: %w", err)
+		}
+		videos, _ := parsePlaylistPage(data, "playlistPanelVideoRenderer")
+		add(videos)
+
+		if playlist.Title == "" {
+			var panel struct {
+				Contents struct {
+					TwoColumnWatchNextResults struct {
+						Playlist struct {
+							Playlist struct {
+								Title string `json:"title"`
+							} `json:"playlist"`
+						} `json:"playlist"`
+					} `json:"twoColumnWatchNextResults"`
+				} `json:"contents"`
+			}
+			if raw, err := json.Marshal(data); err == nil && json.Unmarshal(raw, &panel) == nil {
+				playlist.Title = panel.Contents.TwoColumnWatchNextResults.Playlist.Playlist.Title
+			}
+		}
+	}
+
+	if len(playlist.Videos) == 0 {
+		return nil, fmt.Errorf("playlist %s is empty, private or does not exist", playlistID)
+	}
+	if playlist.Title == "" {
+		playlist.Title = playlistID
+	}
+
+	return playlist, nil
+}
+
+// innerTubeWeb calls an InnerTube endpoint as the web client and returns
+// the decoded JSON response
+func (f *TranscriptFetcher) innerTubeWeb(ctx context.Context, endpoint string, body map[string]interface{}) (map[string]interface{}, error) {
+	body["context"] = map[string]interface{}{
+		"client": map[string]string{
+			"clientName":    "WEB",
+			"clientVersion": "2.20250101.00.00",
+			"hl":            "en",
+		},
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal InnerTube request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://www.youtube.com/youtubei/v1/"+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create InnerTube request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en-US")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch InnerTube data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("InnerTube API returned status: %d", resp.StatusCode)
+	}
+
+	var data map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to parse InnerTube response: %w", err)
+	}
+	return data, nil
+}
+
+// parsePlaylistPage collects the videos rendered with the given renderer and
+// the continuation token for the next page, if any. The response layout
+// changes often, so the whole tree is searched rather than a fixed path.
+func parsePlaylistPage(data map[string]interface{}, renderer string) ([]PlaylistVideo, string) {
+	var videos []PlaylistVideo
+	var token string
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if r, ok := n[renderer].(map[string]interface{}); ok {
+				if id, _ := r["videoId"].(string); id != "" {
+					videos = append(videos, PlaylistVideo{VideoID: id, Title: text(r["title"])})
+				}
+				return
+			}
+			if cmd, ok := n["continuationCommand"].(map[string]interface{}); ok && token == "" {
+				token, _ = cmd["token"].(string)
+			}
+			for _, child := range n {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(data)
+
+	return videos, token
+}
+
+// playlistTitle reads the playlist title from a browse response
+func playlistTitle(data map[string]interface{}) string {
+	metadata, _ := data["metadata"].(map[string]interface{})
+	renderer, _ := metadata["playlistMetadataRenderer"].(map[string]interface{})
+	title, _ := renderer["title"].(string)
+	return title
+}
+
+// text reads an InnerTube text object, which is either {"simpleText": ...}
+// or {"runs": [{"text": ...}, ...]}
+func text(node interface{}) string {
+	n, ok := node.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if s, ok := n["simpleText"].(string); ok {
+		return s
+	}
+
+	var out strings.Builder
+	runs, _ := n["runs"].([]interface{})
+	for _, run := range runs {
+		if r, ok := run.(map[string]interface{}); ok {
+			s, _ := r["text"].(string)
+			out.WriteString(s)
+		}
+	}
+	return out.String()
+}
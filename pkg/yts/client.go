@@ -0,0 +1,472 @@
+// Package yts fetches YouTube transcripts and summarizes or answers
+// questions about them with an LLM provider. It is the library behind the
+// yts command, for embedding in other Go programs.
+//
+//	client, err := yts.New(yts.WithProviderName("ollama"))
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := client.Summarize(ctx, "https://www.youtube.com/watch?v=...", yts.Style("long"))
+//
+// A Client doesn't read the yts config file, and YTS_* environment variables
+// don't change its settings. Start from DefaultConfig and pass it with
+// WithConfig to change them.
+//
+// API keys for hosted providers given with WithAPIKey or WithSecretStore are
+// the only keys the client uses. Without either option, keys are looked up
+// like the yts command does, in the stores listed in the config's
+// secrets.order: YTS_<PROVIDER>_API_KEY environment variables, the OS
+// keyring and the encrypted key file by default.
+package yts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/conormkelly/yts-cli/internal/chunk"
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/internal/constants"
+	"github.com/conormkelly/yts-cli/internal/llm"
+	"github.com/conormkelly/yts-cli/internal/prompt"
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// Client fetches transcripts and generates text from them. It is safe for
+// concurrent use.
+type Client struct {
+	cfg             *config.Config
+	httpClient      *http.Client
+	transcriptCache transcript.Cache
+	responseCache   llm.ResponseCache
+	maxConcurrent   int
+	limiter         *llm.Limiter // Shared by every provider, nil for no limit
+	fetcher         *transcript.TranscriptFetcher
+
+	// Applied in New, after every option has run
+	providerName string
+	apiKeys      map[string]string
+	secretStores []SecretStore
+
+	mu             sync.Mutex
+	provider       llm.Provider
+	customProvider bool
+	styleProviders map[string]llm.Provider // Keyed by provider and model override
+}
+
+// Response is the result of Summarize or Ask
+type Response struct {
+	Transcript *Transcript
+	Text       string
+}
+
+// New creates a client. Without options it uses DefaultConfig, fetches
+// without caching, and talks to the default provider (LM Studio).
+func New(opts ...Option) (*Client, error) {
+	c := &Client{cfg: config.Default(), styleProviders: map[string]llm.Provider{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.customProvider = c.provider != nil
+	if c.maxConcurrent > 0 {
+		c.limiter = llm.NewLimiter(c.maxConcurrent)
+		if c.customProvider {
+			c.provider = c.limiter.Limit(c.provider)
+		}
+	}
+
+	if c.providerName != "" {
+		copied := *c.cfg
+		copied.Provider = c.providerName
+		c.cfg = &copied
+	}
+
+	var stores []SecretStore
+	if len(c.apiKeys) > 0 {
+		stores = append(stores, config.NewMemoryStore(c.apiKeys))
+	}
+	stores = append(stores, c.secretStores...)
+	if len(stores) > 0 {
+		c.cfg = c.cfg.WithSecretStores(stores...)
+	}
+
+	fetcherOpts := []transcript.Option{}
+	if c.httpClient != nil {
+		fetcherOpts = append(fetcherOpts, transcript.WithHTTPClient(c.httpClient))
+	}
+	if c.transcriptCache != nil {
+		fetcherOpts = append(fetcherOpts, transcript.WithCache(c.transcriptCache))
+	}
+	c.fetcher = transcript.NewTranscriptFetcher(fetcherOpts...)
+
+	return c, nil
+}
+
+// Config returns the configuration the client was created with
+func (c *Client) Config() *Config {
+	return c.cfg
+}
+
+// Provider returns the LLM provider, creating it on first use. Creating a
+// hosted provider fails if its API key can't be found (see WithAPIKey), so
+// clients that only fetch transcripts never need one.
+func (c *Client) Provider() (Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	provider, err := c.newProvider(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.provider = provider
+	return provider, nil
+}
+
+// newProvider creates a provider for cfg with the client's response cache.
+// Its requests count against the client-wide concurrency limit.
+func (c *Client) newProvider(cfg *config.Config) (llm.Provider, error) {
+	provider, err := llm.NewProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize llm client: %w", err)
+	}
+	if c.responseCache != nil {
+		provider = llm.WithResponseCache(provider, cfg, c.responseCache)
+	}
+	if c.limiter != nil {
+		provider = c.limiter.Limit(provider)
+	}
+	return provider, nil
+}
+
+// styleProvider returns the provider and chunking settings for a summary
+// style, applying its overrides. Provider and model overrides are ignored
+// when the client was created with WithProvider.
+func (c *Client) styleProvider(style config.SummaryStyle) (llm.Provider, config.ChunkingConfig, error) {
+	cfg := c.cfg
+	provider, err := c.Provider()
+	if err != nil {
+		return nil, config.ChunkingConfig{}, err
+	}
+
+	if (style.Provider != "" || style.Model != "") && !c.customProvider {
+		cfg = c.cfg.WithSummaryStyle(style)
+		key := cfg.Provider + "/" + style.Model
+
+		c.mu.Lock()
+		cached, ok := c.styleProviders[key]
+		if !ok {
+			cached, err = c.newProvider(cfg)
+			if err == nil {
+				c.styleProviders[key] = cached
+			}
+		}
+		c.mu.Unlock()
+		if err != nil {
+			return nil, config.ChunkingConfig{}, err
+		}
+		provider = cached
+	}
+
+	if style.Temperature != nil {
+		provider = llm.WithDefaults(provider, llm.Options{Temperature: style.Temperature})
+	}
+	return provider, cfg.GetChunking(), nil
+}
+
+// Tracks returns the video title and every caption track available for it
+func (c *Client) Tracks(ctx context.Context, videoURL string) (string, []CaptionTrack, error) {
+	return c.fetcher.ListTracks(ctx, videoURL)
+}
+
+// Playlist lists the videos in a playlist
+func (c *Client) Playlist(ctx context.Context, playlistURL string) (*Playlist, error) {
+	return c.fetcher.FetchPlaylist(ctx, playlistURL)
+}
+
+// Transcript fetches a video's transcript. When TranslateTo is set and
+// YouTube can't translate the captions, the provider translates them, and
+// the translation is cached in place of the untranslated transcript.
+func (c *Client) Transcript(ctx context.Context, videoURL string, opts ...RequestOption) (*Transcript, error) {
+	o := newRequestOptions(opts)
+
+	result, err := c.fetcher.Fetch(ctx, videoURL, o.fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.NeedsTranslation(o.fetch.TranslateTo) {
+		provider, err := c.Provider()
+		if err != nil {
+			return nil, err
+		}
+		o.status(fmt.Sprintf("YouTube could not translate the captions, translating with %s...", c.cfg.Provider))
+
+		entries, err := translateEntries(ctx, result.Entries, o.fetch.TranslateTo, provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate transcript: %w", err)
+		}
+		result.Entries = entries
+		result.Language = o.fetch.TranslateTo
+		result.TranslatedBy = c.cfg.Provider
+
+		// The fetch's cache key includes the target language, so later
+		// fetches get the translation instead of translating again.
+		// Caching is best-effort, as in the fetcher.
+		if c.transcriptCache != nil {
+			_ = c.transcriptCache.Put(result.VideoID, o.fetch.CacheKey(), result)
+		}
+	}
+
+	return result, nil
+}
+
+// Summarize fetches a video's transcript and summarizes it
+func (c *Client) Summarize(ctx context.Context, videoURL string, opts ...RequestOption) (*Response, error) {
+	result, err := c.Transcript(ctx, videoURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := c.SummarizeTranscript(ctx, result, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Transcript: result, Text: text}, nil
+}
+
+// SummarizeTranscript summarizes an already fetched transcript. Transcripts
+// longer than the provider's chunk size are summarized section by section,
+// then combined. On error, the text generated so far is returned with it.
+func (c *Client) SummarizeTranscript(ctx context.Context, t *Transcript, opts ...RequestOption) (string, error) {
+	o := newRequestOptions(opts)
+
+	name := styleName(o.style)
+	style, err := c.cfg.SummaryStyle(name)
+	if err != nil {
+		return "", err
+	}
+
+	systemPrompt, err := renderPrompt("summaries."+name+".system_prompt", style.SystemPrompt, t, "")
+	if err != nil {
+		return "", err
+	}
+
+	provider, chunking, err := c.styleProvider(style)
+	if err != nil {
+		return "", err
+	}
+	chunking.ReducePrompt, err = renderPrompt("chunking.reduce_prompt", chunking.ReducePrompt, t, "")
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	err = chunk.Summarize(ctx, provider, t, systemPrompt, chunking, o.onProgress, o.text(&text))
+	return text.String(), err
+}
+
+// Ask fetches a video's transcript and answers a question about it
+func (c *Client) Ask(ctx context.Context, videoURL string, question string, opts ...RequestOption) (*Response, error) {
+	result, err := c.Transcript(ctx, videoURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := c.AskTranscript(ctx, result, question, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Transcript: result, Text: text}, nil
+}
+
+// AskTranscript answers a question about an already fetched transcript.
+// On error, the text generated so far is returned with it.
+func (c *Client) AskTranscript(ctx context.Context, t *Transcript, question string, opts ...RequestOption) (string, error) {
+	o := newRequestOptions(opts)
+
+	if strings.TrimSpace(question) == "" {
+		return "", fmt.Errorf("question is required")
+	}
+
+	provider, err := c.Provider()
+	if err != nil {
+		return "", err
+	}
+
+	systemPrompt, err := renderPrompt("queries.system_prompt", c.cfg.Queries.SystemPrompt, t, question)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	err = provider.Stream(ctx, systemPrompt, t.Text(), o.text(&text))
+	return text.String(), err
+}
+
+// FormatTranscript asks the provider to add punctuation and paragraphs to a
+// transcript. On error, the text generated so far is returned with it.
+func (c *Client) FormatTranscript(ctx context.Context, t *Transcript, opts ...RequestOption) (string, error) {
+	o := newRequestOptions(opts)
+
+	provider, err := c.Provider()
+	if err != nil {
+		return "", err
+	}
+
+	systemPrompt, err := renderPrompt("transcripts.system_prompt", c.cfg.Transcripts.SystemPrompt, t, "")
+	if err != nil {
+		return "", err
+	}
+
+	input := t.Text()
+	if o.timestamps {
+		input = t.TimestampedText()
+	}
+
+	var text strings.Builder
+	err = provider.Stream(ctx, systemPrompt, input, o.text(&text))
+	return text.String(), err
+}
+
+// Chat continues a conversation about a transcript. history holds the
+// conversation so far and must end with the user's latest message.
+// On error, the text generated so far is returned with it.
+func (c *Client) Chat(ctx context.Context, t *Transcript, history []Message, opts ...RequestOption) (string, error) {
+	o := newRequestOptions(opts)
+
+	provider, err := c.Provider()
+	if err != nil {
+		return "", err
+	}
+
+	systemPrompt, err := renderPrompt("chat.system_prompt", c.cfg.Chat.SystemPrompt, t, "")
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	_, err = provider.Generate(ctx, &llm.Request{
+		System:   systemPrompt,
+		Messages: history,
+	}, o.text(&text))
+	return text.String(), err
+}
+
+// PlaylistOverview combines summaries of a playlist's videos into an
+// overview of the whole playlist. Summaries that don't fit the provider's
+// chunk size are condensed in groups first. On error, the text generated
+// so far is returned with it.
+func (c *Client) PlaylistOverview(ctx context.Context, playlist *Playlist, summaries []string, opts ...RequestOption) (string, error) {
+	o := newRequestOptions(opts)
+
+	data := prompt.Data{Title: playlist.Title}
+	systemPrompt, err := prompt.Render("playlist overview prompt", constants.PlaylistOverviewPrompt, data)
+	if err != nil {
+		return "", err
+	}
+	groupPrompt, err := prompt.Render("playlist group prompt", constants.PlaylistGroupPrompt, data)
+	if err != nil {
+		return "", err
+	}
+
+	provider, err := c.Provider()
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	err = chunk.Reduce(ctx, provider, summaries, chunk.ReduceOptions{
+		GroupPrompt: groupPrompt,
+		Prompt:      systemPrompt,
+		MaxTokens:   c.cfg.GetChunking().ChunkTokens,
+	}, o.text(&text))
+	return text.String(), err
+}
+
+// SummaryPrompt returns the system prompt for a summary style, rendered
+// for t
+func (c *Client) SummaryPrompt(t *Transcript, style string) (string, error) {
+	name := styleName(style)
+	s, err := c.cfg.SummaryStyle(name)
+	if err != nil {
+		return "", err
+	}
+	return renderPrompt("summaries."+name+".system_prompt", s.SystemPrompt, t, "")
+}
+
+// ValidateStyle reports an error if style isn't a configured summary style
+func (c *Client) ValidateStyle(style string) error {
+	_, err := c.cfg.SummaryStyle(styleName(style))
+	return err
+}
+
+// Styles returns the names of the configured summary styles, sorted
+func (c *Client) Styles() []string {
+	return c.cfg.SummaryStyleNames()
+}
+
+// DefaultStyle is the summary style used when none is given
+const DefaultStyle = "short"
+
+func styleName(style string) string {
+	if style == "" {
+		return DefaultStyle
+	}
+	return style
+}
+
+// renderPrompt renders a configured prompt template with the transcript's
+// video details. name is the prompt's config key, for errors.
+func renderPrompt(name string, text string, t *Transcript, query string) (string, error) {
+	data := prompt.NewData(t)
+	data.Query = query
+	return prompt.Render(name, text, data)
+}
+
+// translationChunkTokens bounds each LLM translation request, since the
+// translated output is roughly as long as the input
+const translationChunkTokens = 2000
+
+// translateEntries translates entries one chunk at a time. When the model keeps
+// the line structure, each translated line keeps its original timing.
+func translateEntries(ctx context.Context, entries []transcript.TranscriptResponse, lang string, provider llm.Provider) ([]transcript.TranscriptResponse, error) {
+	systemPrompt, err := prompt.Render("translation prompt", constants.TranslationPrompt, prompt.Data{Language: lang})
+	if err != nil {
+		return nil, err
+	}
+
+	var translated []transcript.TranscriptResponse
+	for _, c := range chunk.Split(entries, translationChunkTokens, 0) {
+		var output strings.Builder
+		err := provider.Stream(ctx, systemPrompt, c.Text(), func(text string) {
+			output.WriteString(text)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var lines []string
+		for _, line := range strings.Split(output.String(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		for i, line := range lines {
+			entry := transcript.TranscriptResponse{Text: line, Start: c.Start()}
+			if len(lines) == len(c.Segments) {
+				entry.Start = c.Segments[i].Start
+				entry.Duration = c.Segments[i].Duration
+			}
+			translated = append(translated, entry)
+		}
+	}
+
+	return translated, nil
+}
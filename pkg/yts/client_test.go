@@ -0,0 +1,172 @@
+package yts_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+)
+
+// newClaudeServer returns a Claude API stub that replies "hello" to
+// requests made with apiKey and rejects any other key
+func newClaudeServer(t *testing.T, apiKey string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != apiKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key %q"}}`, got)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: content_block_delta\n")
+		fmt.Fprint(w, `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"hello"}}`+"\n\n")
+		fmt.Fprint(w, "event: message_stop\n")
+		fmt.Fprint(w, `data: {"type":"message_stop"}`+"\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// claudeConfig returns the default config pointed at a Claude stub
+func claudeConfig(baseURL string) *yts.Config {
+	cfg := yts.DefaultConfig()
+	cfg.Providers.Claude.BaseURL = baseURL
+	cfg.Providers.Claude.MaxRetries = 0
+	return cfg
+}
+
+// mapStore is a SecretStore backed by a map
+type mapStore map[string]string
+
+func (s mapStore) Name() string { return "test" }
+
+func (s mapStore) Get(provider string) (string, error) {
+	if apiKey, ok := s[provider]; ok {
+		return apiKey, nil
+	}
+	return "", yts.ErrSecretNotFound
+}
+
+func (s mapStore) Set(provider, apiKey string) error {
+	s[provider] = apiKey
+	return nil
+}
+
+func (s mapStore) Delete(provider string) error {
+	delete(s, provider)
+	return nil
+}
+
+func TestAPIKeyOptions(t *testing.T) {
+	// Keys in the environment must not be used once keys are given
+	t.Setenv("YTS_CLAUDE_API_KEY", "sk-from-env")
+
+	tests := []struct {
+		name    string
+		opts    []yts.Option
+		wantErr string
+	}{
+		{
+			name: "WithAPIKey",
+			opts: []yts.Option{yts.WithAPIKey("claude", "sk-from-option")},
+		},
+		{
+			name: "WithSecretStore",
+			opts: []yts.Option{yts.WithSecretStore(mapStore{"claude": "sk-from-option"})},
+		},
+		{
+			name: "WithAPIKey before WithSecretStore",
+			opts: []yts.Option{
+				yts.WithSecretStore(mapStore{"claude": "sk-from-store"}),
+				yts.WithAPIKey("claude", "sk-from-option"),
+			},
+		},
+		{
+			name:    "no fallback to the environment",
+			opts:    []yts.Option{yts.WithAPIKey("openai", "sk-openai")},
+			wantErr: "no API key for claude",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newClaudeServer(t, "sk-from-option")
+			opts := append([]yts.Option{yts.WithConfig(claudeConfig(srv.URL)), yts.WithProviderName("claude")}, tt.opts...)
+			client, err := yts.New(opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			answer, err := client.AskTranscript(context.Background(), &yts.Transcript{Title: "Test Video"}, "Hi?")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AskTranscript() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AskTranscript() error = %v", err)
+			}
+			if answer != "hello" {
+				t.Errorf("AskTranscript() = %q, want hello", answer)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentRequestsAcrossStyles(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var running, peak int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"summary"},"done":true}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := yts.DefaultConfig()
+	cfg.Provider = "ollama"
+	cfg.Providers.Ollama.BaseURL = srv.URL
+	cfg.Summaries["mistral"] = yts.SummaryStyle{SystemPrompt: "Summarize.", Model: "mistral"}
+	cfg.Summaries["qwen"] = yts.SummaryStyle{SystemPrompt: "Summarize.", Model: "qwen"}
+
+	client, err := yts.New(yts.WithConfig(cfg), yts.WithMaxConcurrentRequests(1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Each style override gets its own provider, but they share the limit
+	var wg sync.WaitGroup
+	for i := range 9 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			style := []string{"short", "mistral", "qwen"}[i%3]
+			if _, err := client.SummarizeTranscript(context.Background(), &yts.Transcript{Title: "Test Video"}, yts.Style(style)); err != nil {
+				t.Errorf("SummarizeTranscript(%s) error = %v", style, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 1 {
+		t.Errorf("peak concurrent requests = %d, want 1", peak)
+	}
+}
@@ -0,0 +1,86 @@
+package yts_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+)
+
+func Example() {
+	client, err := yts.New(yts.WithProviderName("ollama"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := client.Summarize(context.Background(), "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		yts.Style("long"),
+		yts.OnText(func(text string) { fmt.Print(text) }),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n\nSummarized %q (%d transcript lines)\n", resp.Transcript.Title, len(resp.Transcript.Entries))
+}
+
+func ExampleWithAPIKey() {
+	client, err := yts.New(
+		yts.WithProviderName("claude"),
+		yts.WithAPIKey("claude", os.Getenv("MY_APP_CLAUDE_KEY")),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := client.Ask(context.Background(), "https://youtu.be/dQw4w9WgXcQ", "What is the song about?")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(resp.Text)
+}
+
+func ExampleWithConfig() {
+	cfg := yts.DefaultConfig()
+	cfg.Summaries["tweet"] = yts.SummaryStyle{
+		SystemPrompt: "Summarize {{.Title}} in a single sentence of under 280 characters.",
+	}
+
+	client, err := yts.New(yts.WithConfig(cfg), yts.WithProviderName("ollama"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(client.Config().Provider, client.Styles())
+	// Output: ollama [long short tweet]
+}
+
+// echoProvider answers every request by repeating the system prompt
+type echoProvider struct{}
+
+func (echoProvider) Generate(ctx context.Context, r *yts.Request, callback func(string)) (*yts.Result, error) {
+	callback(r.System)
+	return &yts.Result{}, nil
+}
+
+func (p echoProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	_, err := p.Generate(ctx, &yts.Request{System: systemPrompt}, callback)
+	return err
+}
+
+func ExampleWithProvider() {
+	cfg := yts.DefaultConfig()
+	cfg.Summaries["short"] = yts.SummaryStyle{SystemPrompt: "Summarize {{.Title}}."}
+
+	client, err := yts.New(yts.WithConfig(cfg), yts.WithProvider(echoProvider{}))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	summary, err := client.SummarizeTranscript(context.Background(), &yts.Transcript{Title: "A Fetched Video"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(summary)
+	// Output: Summarize A Fetched Video.
+}
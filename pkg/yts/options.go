@@ -0,0 +1,271 @@
+package yts
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conormkelly/yts-cli/internal/cache"
+	"github.com/conormkelly/yts-cli/internal/config"
+	"github.com/conormkelly/yts-cli/internal/llm"
+	"github.com/conormkelly/yts-cli/internal/transcript"
+)
+
+// Types shared with the rest of yts
+type (
+	Config          = config.Config
+	SummaryStyle    = config.SummaryStyle
+	Transcript      = transcript.Transcript
+	CaptionTrack    = transcript.CaptionTrack
+	VideoInfo       = transcript.VideoInfo
+	Chapter         = transcript.Chapter
+	Playlist        = transcript.Playlist
+	PlaylistVideo   = transcript.PlaylistVideo
+	Provider        = llm.Provider
+	Request         = llm.Request
+	Result          = llm.Result
+	Message         = llm.Message
+	TranscriptCache = transcript.Cache
+	ResponseCache   = llm.ResponseCache
+	SecretStore     = config.SecretStore
+)
+
+// ErrSecretNotFound is returned by a SecretStore that has no key for a provider
+var ErrSecretNotFound = config.ErrSecretNotFound
+
+// Errors returned by Transcript and the methods built on it. Use errors.As
+// with a pointer, e.g. var disabled *yts.ErrTranscriptsDisabled.
+type (
+	ErrTranscriptsDisabled    = transcript.ErrTranscriptsDisabled
+	ErrNoTranscriptFound      = transcript.ErrNoTranscriptFound
+	ErrNoTranscriptInLanguage = transcript.ErrNoTranscriptInLanguage
+	ErrPlaylistURL            = transcript.ErrPlaylistURL
+	ErrInvalidURL             = transcript.ErrInvalidURL
+)
+
+// Message roles for Chat
+const (
+	RoleUser      = llm.RoleUser
+	RoleAssistant = llm.RoleAssistant
+)
+
+// DefaultConfig returns the built-in configuration
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// ExtractVideoID returns the 11-character video ID from a YouTube URL
+func ExtractVideoID(url string) (string, error) {
+	return transcript.ExtractVideoID(url)
+}
+
+// IsPlaylistURL reports whether url points at a playlist rather than a
+// single video
+func IsPlaylistURL(url string) bool {
+	return transcript.IsPlaylistURL(url)
+}
+
+// DefaultConcurrency returns how many LLM requests to send a provider at
+// once: 1 for local servers such as LM Studio and Ollama, more otherwise.
+// See WithMaxConcurrentRequests.
+func DefaultConcurrency(providerName string) int {
+	return llm.DefaultConcurrency(providerName)
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithConfig replaces the default configuration
+func WithConfig(cfg *Config) Option {
+	return func(c *Client) {
+		copied := *cfg
+		c.cfg = &copied
+	}
+}
+
+// WithProviderName selects one of the configured providers: lmstudio,
+// ollama, claude, openai, gemini or the name of an endpoint. It applies
+// whether it comes before or after WithConfig.
+func WithProviderName(name string) Option {
+	return func(c *Client) {
+		c.providerName = name
+	}
+}
+
+// WithAPIKey uses apiKey for provider: claude, openai, gemini or an
+// endpoint's api_key_ref. Once any key or store is given with WithAPIKey or
+// WithSecretStore, keys are looked up only there, never in the environment,
+// OS keyring or encrypted key file.
+func WithAPIKey(provider, apiKey string) Option {
+	return func(c *Client) {
+		if c.apiKeys == nil {
+			c.apiKeys = map[string]string{}
+		}
+		c.apiKeys[provider] = apiKey
+	}
+}
+
+// WithSecretStore looks API keys up in store, after any keys given with
+// WithAPIKey. See WithAPIKey.
+func WithSecretStore(store SecretStore) Option {
+	return func(c *Client) {
+		c.secretStores = append(c.secretStores, store)
+	}
+}
+
+// WithProvider uses p instead of creating a provider from the config
+func WithProvider(p Provider) Option {
+	return func(c *Client) {
+		c.provider = p
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to talk to YouTube
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithTranscriptCache stores fetched transcripts in cache and reuses them
+func WithTranscriptCache(cache TranscriptCache) Option {
+	return func(c *Client) {
+		c.transcriptCache = cache
+	}
+}
+
+// WithResponseCache replays identical LLM requests from cache. It has no
+// effect when combined with WithProvider.
+func WithResponseCache(cache ResponseCache) Option {
+	return func(c *Client) {
+		c.responseCache = cache
+	}
+}
+
+// WithMaxConcurrentRequests limits how many LLM requests run at once
+// across all calls on the client. Local servers such as LM Studio
+// generally handle one at a time.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		c.maxConcurrent = n
+	}
+}
+
+// NewMemoryTranscriptCache returns a transcript cache that lives as long
+// as the process
+func NewMemoryTranscriptCache() TranscriptCache {
+	return transcript.NewMemoryCache()
+}
+
+// NewFileTranscriptCache returns a transcript cache stored under dir.
+// Entries older than ttl are ignored; a ttl of zero never expires them.
+func NewFileTranscriptCache(dir string, ttl time.Duration) TranscriptCache {
+	return cache.NewTranscriptStore(dir, ttl)
+}
+
+// NewFileResponseCache returns an LLM response cache stored under dir
+func NewFileResponseCache(dir string) ResponseCache {
+	return cache.NewResponseStore(dir)
+}
+
+// DefaultCacheDir returns the cache directory used by the yts command
+func DefaultCacheDir() (string, error) {
+	return cache.Dir()
+}
+
+// RequestOption configures a single call
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	fetch      transcript.FetchOptions
+	style      string
+	timestamps bool
+	onText     func(string)
+	onProgress func(current, total int)
+	onStatus   func(string)
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// text returns a callback that collects generated text into b and passes
+// it on to OnText
+func (o *requestOptions) text(b *strings.Builder) func(string) {
+	return func(text string) {
+		b.WriteString(text)
+		if o.onText != nil {
+			o.onText(text)
+		}
+	}
+}
+
+func (o *requestOptions) status(msg string) {
+	if o.onStatus != nil {
+		o.onStatus(msg)
+	}
+}
+
+// Languages sets the preferred caption languages in priority order
+func Languages(langs ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.fetch.Languages = langs
+	}
+}
+
+// TranslateTo translates the transcript into lang, using YouTube's
+// translation when available and the provider otherwise
+func TranslateTo(lang string) RequestOption {
+	return func(o *requestOptions) {
+		o.fetch.TranslateTo = lang
+	}
+}
+
+// Refresh re-fetches the transcript instead of using a cached copy
+func Refresh() RequestOption {
+	return func(o *requestOptions) {
+		o.fetch.Refresh = true
+	}
+}
+
+// Timestamps includes each line's start time in the text sent to
+// FormatTranscript
+func Timestamps() RequestOption {
+	return func(o *requestOptions) {
+		o.timestamps = true
+	}
+}
+
+// Style selects the summary style: short (the default), long, or any
+// other style defined in the config's summaries
+func Style(name string) RequestOption {
+	return func(o *requestOptions) {
+		o.style = name
+	}
+}
+
+// OnText streams generated text to fn as it arrives
+func OnText(fn func(text string)) RequestOption {
+	return func(o *requestOptions) {
+		o.onText = fn
+	}
+}
+
+// OnProgress is called before each section of a long transcript is summarized
+func OnProgress(fn func(current, total int)) RequestOption {
+	return func(o *requestOptions) {
+		o.onProgress = fn
+	}
+}
+
+// OnStatus receives progress messages, such as falling back to the
+// provider for translation
+func OnStatus(fn func(msg string)) RequestOption {
+	return func(o *requestOptions) {
+		o.onStatus = fn
+	}
+}
@@ -0,0 +1,296 @@
+package yts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/conormkelly/yts-cli/pkg/yts"
+)
+
+const testVideoID = "dQw4w9WgXcQ"
+
+// newYouTubeClient returns an HTTP client that sends every request, whatever
+// its host, to a stub of the YouTube endpoints the transcript fetcher uses
+func newYouTubeClient(t *testing.T) *http.Client {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/watch":
+			fmt.Fprint(w, `<html><head><title>Test Video - YouTube</title></head>
+<body><script>ytcfg.set({"INNERTUBE_API_KEY": "test-key"});</script>
+<script>{"uploadDate":"2024-05-01T00:00:00-07:00"}</script></body></html>`)
+		case "/youtubei/v1/player":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"playabilityStatus": map[string]string{"status": "OK"},
+				"videoDetails": map[string]string{
+					"author":           "Test Channel",
+					"lengthSeconds":    "125",
+					"shortDescription": "0:00 Intro\n1:00 Middle\n2:00 End",
+				},
+				"captions": map[string]interface{}{
+					"playerCaptionsTracklistRenderer": map[string]interface{}{
+						"captionTracks": []map[string]interface{}{{
+							"baseUrl":      srv.URL + "/api/timedtext?v=" + testVideoID + "&lang=en",
+							"name":         map[string]interface{}{"runs": []map[string]string{{"text": "English"}}},
+							"languageCode": "en",
+						}},
+					},
+				},
+			})
+		case "/api/timedtext":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><transcript>`+
+				`<text start="0" dur="2.5">Hello &amp;amp; welcome</text>`+
+				`<text start="60" dur="3">The middle part</text>`+
+				`<text start="120" dur="5">Goodbye</text></transcript>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubProvider replies with a fixed text, recording the prompts it was sent
+type stubProvider struct {
+	reply string
+
+	mu      sync.Mutex
+	systems []string
+	inputs  []string
+}
+
+func (p *stubProvider) Generate(ctx context.Context, r *yts.Request, callback func(string)) (*yts.Result, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *stubProvider) Stream(ctx context.Context, systemPrompt string, transcript string, callback func(string)) error {
+	p.mu.Lock()
+	p.systems = append(p.systems, systemPrompt)
+	p.inputs = append(p.inputs, transcript)
+	p.mu.Unlock()
+
+	for _, word := range strings.SplitAfter(p.reply, " ") {
+		callback(word)
+	}
+	return nil
+}
+
+func TestTranscript(t *testing.T) {
+	t.Parallel()
+
+	client, err := yts.New(yts.WithHTTPClient(newYouTubeClient(t)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := client.Transcript(context.Background(), "https://www.youtube.com/watch?v="+testVideoID)
+	if err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+
+	if result.VideoID != testVideoID || result.Title != "Test Video" || result.Language != "en" {
+		t.Errorf("Transcript() = %s %q in %s, want %s %q in en", result.VideoID, result.Title, result.Language, testVideoID, "Test Video")
+	}
+	if want := "Hello & welcome\nThe middle part\nGoodbye\n"; result.Text() != want {
+		t.Errorf("Text() = %q, want %q", result.Text(), want)
+	}
+	if result.Channel != "Test Channel" || result.UploadDate != "2024-05-01" || result.Duration != 125 {
+		t.Errorf("VideoInfo = %+v", result.VideoInfo)
+	}
+	if len(result.Chapters) != 3 || result.Chapters[1].Title != "Middle" || result.Chapters[1].Start != 60 {
+		t.Errorf("Chapters = %+v, want Intro, Middle and End", result.Chapters)
+	}
+}
+
+func TestTranscriptTranslationCached(t *testing.T) {
+	t.Parallel()
+
+	// YouTube can't translate the stub's captions, so the provider does
+	provider := &stubProvider{reply: "Bonjour et bienvenue\nLa partie du milieu\nAu revoir"}
+	client, err := yts.New(
+		yts.WithProvider(provider),
+		yts.WithHTTPClient(newYouTubeClient(t)),
+		yts.WithTranscriptCache(yts.NewMemoryTranscriptCache()),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := range 2 {
+		result, err := client.Transcript(context.Background(), "https://youtu.be/"+testVideoID, yts.TranslateTo("fr"))
+		if err != nil {
+			t.Fatalf("Transcript() error = %v", err)
+		}
+		if result.Language != "fr" || result.TranslatedBy == "" {
+			t.Errorf("fetch %d: Language = %q, TranslatedBy = %q, want an LLM translation to fr", i+1, result.Language, result.TranslatedBy)
+		}
+		if want := "Bonjour et bienvenue\nLa partie du milieu\nAu revoir\n"; result.Text() != want {
+			t.Errorf("fetch %d: Text() = %q, want %q", i+1, result.Text(), want)
+		}
+		if result.Entries[1].Start != 60 {
+			t.Errorf("fetch %d: second line starts at %v, want the original timing of 60", i+1, result.Entries[1].Start)
+		}
+	}
+
+	if len(provider.systems) != 1 {
+		t.Errorf("provider calls = %d, want 1, with the second fetch served from cache", len(provider.systems))
+	}
+
+	// The untranslated transcript is still fetched on its own
+	result, err := client.Transcript(context.Background(), "https://youtu.be/"+testVideoID)
+	if err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	if result.Language != "en" || result.TranslatedBy != "" {
+		t.Errorf("untranslated fetch: Language = %q, TranslatedBy = %q, want en", result.Language, result.TranslatedBy)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	cfg := yts.DefaultConfig()
+	cfg.Summaries["brief"] = yts.SummaryStyle{SystemPrompt: "Summarize {{.Title}} by {{.Channel}} in one line."}
+
+	provider := &stubProvider{reply: "A short test video."}
+	var streamed strings.Builder
+	client, err := yts.New(
+		yts.WithConfig(cfg),
+		yts.WithProvider(provider),
+		yts.WithHTTPClient(newYouTubeClient(t)),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	resp, err := client.Summarize(context.Background(), "https://youtu.be/"+testVideoID,
+		yts.Style("brief"),
+		yts.OnText(func(text string) { streamed.WriteString(text) }),
+	)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if resp.Text != "A short test video." || streamed.String() != resp.Text {
+		t.Errorf("Summarize() = %q, streamed %q, want the provider's reply", resp.Text, streamed.String())
+	}
+	if resp.Transcript.Title != "Test Video" {
+		t.Errorf("Transcript.Title = %q, want Test Video", resp.Transcript.Title)
+	}
+	if len(provider.systems) != 1 {
+		t.Fatalf("provider calls = %d, want 1", len(provider.systems))
+	}
+	if want := "Summarize Test Video by Test Channel in one line."; provider.systems[0] != want {
+		t.Errorf("system prompt = %q, want %q", provider.systems[0], want)
+	}
+	if provider.inputs[0] != resp.Transcript.Text() {
+		t.Errorf("input = %q, want the transcript text", provider.inputs[0])
+	}
+
+	if _, err := client.Summarize(context.Background(), "https://youtu.be/"+testVideoID, yts.Style("missing")); err == nil {
+		t.Error("Summarize() with an unknown style succeeded, want an error")
+	}
+}
+
+func TestWithProviderNameOrder(t *testing.T) {
+	t.Parallel()
+
+	cfg := yts.DefaultConfig()
+	cfg.Provider = "openai"
+
+	for name, opts := range map[string][]yts.Option{
+		"before WithConfig": {yts.WithProviderName("ollama"), yts.WithConfig(cfg)},
+		"after WithConfig":  {yts.WithConfig(cfg), yts.WithProviderName("ollama")},
+	} {
+		client, err := yts.New(opts...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := client.Config().Provider; got != "ollama" {
+			t.Errorf("%s: provider = %q, want ollama", name, got)
+		}
+	}
+	if cfg.Provider != "openai" {
+		t.Errorf("New() changed the caller's config provider to %q", cfg.Provider)
+	}
+}
+
+func TestPlaylistOverview(t *testing.T) {
+	t.Parallel()
+
+	cfg := yts.DefaultConfig()
+	cfg.Provider = "ollama"
+	cfg.Providers.Ollama.Chunking.ChunkTokens = 40
+	playlist := &yts.Playlist{Title: "Go Basics"}
+
+	tests := []struct {
+		name       string
+		summaries  []string
+		wantGroups int
+	}{
+		{name: "fits in one request", summaries: []string{"Video 1: Intro\n\nHello", "Video 2: Types\n\nInts"}},
+		{name: "condensed in groups", summaries: []string{
+			"Video 1: Intro\n\n" + strings.Repeat("a", 100),
+			"Video 2: Types\n\n" + strings.Repeat("b", 100),
+			"Video 3: Funcs\n\n" + strings.Repeat("c", 100),
+		}, wantGroups: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &stubProvider{reply: "The overview."}
+			client, err := yts.New(yts.WithConfig(cfg), yts.WithProvider(provider))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var streamed strings.Builder
+			overview, err := client.PlaylistOverview(context.Background(), playlist, tt.summaries, yts.OnText(func(text string) {
+				streamed.WriteString(text)
+			}))
+			if err != nil {
+				t.Fatalf("PlaylistOverview() error = %v", err)
+			}
+
+			if overview != "The overview." || streamed.String() != overview {
+				t.Errorf("PlaylistOverview() = %q, streamed %q, want only the final overview", overview, streamed.String())
+			}
+			if len(provider.systems) != tt.wantGroups+1 {
+				t.Fatalf("provider calls = %d, want %d", len(provider.systems), tt.wantGroups+1)
+			}
+			for i, system := range provider.systems {
+				if !strings.Contains(system, `Playlist title: "Go Basics"`) {
+					t.Errorf("call %d system prompt doesn't name the playlist: %q", i+1, system)
+				}
+			}
+			if final := provider.systems[len(provider.systems)-1]; !strings.Contains(final, "course overview of the playlist as a whole") {
+				t.Errorf("final system prompt = %q, want the overview prompt", final)
+			}
+		})
+	}
+}